@@ -0,0 +1,110 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ClusterEgressSpec defines the desired state of ClusterEgress
+type ClusterEgressSpec struct {
+	// Namespace is the namespace where egress pods and the Service for
+	// the ClusterEgress run.  They are named "cluster-" followed by the
+	// name of the ClusterEgress.  This field is unchangeable.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	EgressSpec `json:",inline"`
+}
+
+func (cs *ClusterEgressSpec) validateCreate() field.ErrorList {
+	var allErrs field.ErrorList
+	p := field.NewPath("spec", "namespace")
+	for _, msg := range apivalidation.ValidateNamespaceName(cs.Namespace, false) {
+		allErrs = append(allErrs, field.Invalid(p, cs.Namespace, msg))
+	}
+	return append(allErrs, cs.EgressSpec.validateCreate()...)
+}
+
+func (cs *ClusterEgressSpec) validateUpdate(old ClusterEgressSpec) field.ErrorList {
+	allErrs := cs.EgressSpec.validateUpdate(old.EgressSpec)
+	if cs.Namespace != old.Namespace {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "namespace"), "unchangeable"))
+	}
+	return allErrs
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:selectorpath=.status.selector,specpath=.spec.replicas,statuspath=.status.replicas
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.namespace"
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.replicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterEgress is the Schema for the clusteregresses API.
+// It is a cluster-scoped version of Egress that pods in any namespace
+// can reference without a namespace.
+type ClusterEgress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterEgressSpec `json:"spec,omitempty"`
+	Status EgressStatus      `json:"status,omitempty"`
+}
+
+// ClusterEgressPrefix is prepended to the name of a ClusterEgress to name
+// the resources created for it in Spec.Namespace.
+const ClusterEgressPrefix = "cluster-"
+
+// ResourceName returns the name of the Deployment, Service, and other
+// resources created for the ClusterEgress in Spec.Namespace.
+// The prefix keeps them apart from the resources of an Egress of the same name.
+func (ce *ClusterEgress) ResourceName() string {
+	return ClusterEgressPrefix + ce.Name
+}
+
+// IsNamespaceAllowed returns true if pods in `ns` can use the ClusterEgress.
+func (ce *ClusterEgress) IsNamespaceAllowed(ns *corev1.Namespace) (bool, error) {
+	if ns.Name == ce.Spec.Namespace || ce.Spec.AllowedNamespaces == nil {
+		return true, nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(ce.Spec.AllowedNamespaces)
+	if err != nil {
+		return false, err
+	}
+	return sel.Matches(labels.Set(ns.Labels)), nil
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterEgressList contains a list of ClusterEgress
+type ClusterEgressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterEgress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterEgress{}, &ClusterEgressList{})
+}
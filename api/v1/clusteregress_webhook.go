@@ -0,0 +1,44 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager setups the webhook for ClusterEgress.
+// Destinations overlapping with `clusterCIDRs` are warned.
+func (r *ClusterEgress) SetupWebhookWithManager(mgr ctrl.Manager, clusterCIDRs []*net.IPNet) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&egressValidator{client: mgr.GetClient(), clusterCIDRs: clusterCIDRs}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-egress-ysksuzuki-com-v1-clusteregress,mutating=true,failurePolicy=fail,sideEffects=None,groups=egress.ysksuzuki.com,resources=clusteregresses,verbs=create;update,versions=v1,name=mclusteregress.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ClusterEgress{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ClusterEgress) Default() {
+	r.Spec.setDefaults()
+}
+
+// +kubebuilder:webhook:path=/validate-egress-ysksuzuki-com-v1-clusteregress,mutating=false,failurePolicy=fail,sideEffects=None,groups=egress.ysksuzuki.com,resources=clusteregresses,verbs=create;update,versions=v1,name=vclusteregress.kb.io,admissionReviewVersions=v1
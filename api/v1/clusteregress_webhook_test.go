@@ -0,0 +1,172 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func makeClusterEgress() *ClusterEgress {
+	return &ClusterEgress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ctest",
+		},
+		Spec: ClusterEgressSpec{
+			Namespace: "default",
+			EgressSpec: EgressSpec{
+				Destinations: []EgressDestination{{CIDR: "10.4.0.0/16"}},
+				Replicas:     1,
+			},
+		},
+	}
+}
+
+var _ = Describe("ClusterEgress Webhook", func() {
+	ctx := context.TODO()
+
+	// ClusterEgress is deleted after each test not to affect Egress tests.
+	AfterEach(func() {
+		r := &ClusterEgress{}
+		r.Name = "ctest"
+		err := k8sClient.Delete(ctx, r)
+		if err == nil {
+			return
+		}
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should create cluster egress with the sane defaults", func() {
+		r := makeClusterEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "fd04::1/120"})
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Spec.Replicas).To(BeNumerically("==", 1))
+		Expect(string(r.Spec.SessionAffinity)).To(Equal("ClientIP"))
+		Expect(r.Spec.Destinations[1].CIDR).To(Equal("fd04::/120"))
+	})
+
+	It("should deny invalid namespace", func() {
+		r := makeClusterEgress()
+		r.Spec.Namespace = ""
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeClusterEgress()
+		r.Spec.Namespace = "Bad_Namespace"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should validate the spec as Egress", func() {
+		r := makeClusterEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "127.0.0.0/8"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeClusterEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.4.1.0/24"})
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should warn destinations overlapping with Egress", func() {
+		eg := makeEgress()
+		eg.Name = "test-for-cluster"
+		eg.Spec.Destinations = []EgressDestination{{CIDR: "10.4.1.0/24"}}
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).NotTo(HaveOccurred())
+		defer k8sClient.Delete(ctx, eg)
+
+		r := makeClusterEgress()
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		v := &egressValidator{client: k8sClient}
+		warnings, err := v.ValidateUpdate(ctx, r, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(
+			"destination 10.4.0.0/16 overlaps with 10.4.1.0/24 of Egress default/test-for-cluster",
+		))
+
+		warnings, err = v.ValidateUpdate(ctx, eg, eg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(
+			"destination 10.4.1.0/24 overlaps with 10.4.0.0/16 of ClusterEgress ctest",
+		))
+	})
+
+	It("should deny names colliding with Egress", func() {
+		eg := makeEgress()
+		eg.Name = "cluster-ctest"
+		eg.Spec.Destinations = []EgressDestination{{CIDR: "10.5.0.0/16"}}
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).NotTo(HaveOccurred())
+
+		r := makeClusterEgress()
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.Namespace = "kube-system"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Delete(ctx, eg)
+		Expect(err).NotTo(HaveOccurred())
+
+		eg = makeEgress()
+		eg.Name = "cluster-ctest"
+		eg.Namespace = "kube-system"
+		eg.Spec.Destinations = []EgressDestination{{CIDR: "10.5.0.0/16"}}
+		err = k8sClient.Create(ctx, eg)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny updating namespace and destinations", func() {
+		r := makeClusterEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Namespace = "kube-system"
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeClusterEgress()
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(r), r)
+		Expect(err).NotTo(HaveOccurred())
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.10.0.0/24"})
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow updating other fields", func() {
+		r := makeClusterEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Replicas = 3
+		r.Spec.AllowedNamespaces = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,626 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net"
+	"reflect"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// EgressSpec defines the desired state of Egress
+type EgressSpec struct {
+	// Destinations is a list of destination networks.
+	// Packets from client pods to these networks are sent to egress pods.
+	// A destination may be included in another destination only to
+	// specify a different next hop for the network.
+	// +kubebuilder:validation:MinItems=1
+	Destinations []EgressDestination `json:"destinations"`
+
+	// Replicas is the desired number of egress (SNAT) pods.
+	// Defaults to 1.
+	// If Autoscaling is specified, the controller updates this field.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas int32 `json:"replicas"`
+
+	// Strategy describes how to replace existing pods with new ones.
+	// Ref. https://pkg.go.dev/k8s.io/api/apps/v1?tab=doc#DeploymentStrategy
+	// +optional
+	Strategy *appsv1.DeploymentStrategy `json:"strategy,omitempty"`
+
+	// Template is an optional template for egress pods.
+	// A container named "egress-gw" is special.  It is the main container of
+	// egress pods and usually is not meant to be modified.
+	// +optional
+	Template *EgressPodTemplate `json:"template,omitempty"`
+
+	// SessionAffinity is to specify the same field of Service for the Egress.
+	// However, the default is changed from None to ClientIP.
+	// Ref. https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ServiceSpec
+	// +kubebuilder:validation:Enum=ClientIP;None
+	// +kubebuilder:default=ClientIP
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityConfig is to specify the same field of Service for Egress.
+	// Ref. https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ServiceSpec
+	// +optional
+	SessionAffinityConfig *corev1.SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
+
+	// Interface is the name of the network interface of egress pods
+	// through which NAT'ed packets go out.
+	// If empty, the interface of the default route is used.
+	// +kubebuilder:validation:MaxLength=15
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// NetworkAttachment is a reference to a NetworkAttachmentDefinition of
+	// Multus in "name" or "namespace/name" format.
+	// If given, egress pods get a secondary interface attached to the network,
+	// and NAT'ed packets go out of the interface through the gateway found in
+	// the network-status annotation of the pod.
+	// The networks annotation in the pod template is overwritten.
+	// +optional
+	NetworkAttachment string `json:"networkAttachment,omitempty"`
+
+	// AllowedNamespaces selects namespaces whose pods can use this Egress.
+	// Pods in the same namespace as the Egress are always allowed.
+	// If nil, pods in any namespace can use this Egress.
+	// To allow only the same namespace, specify a selector that matches
+	// no namespaces such as `{matchLabels: {kubernetes.io/metadata.name: ""}}`.
+	// +optional
+	AllowedNamespaces *metav1.LabelSelector `json:"allowedNamespaces,omitempty"`
+
+	// Autoscaling lets the controller scale egress pods by the number of
+	// client pods and the throughput of egress pods.
+	// Do not use this together with HorizontalPodAutoscaler for the Egress.
+	// +optional
+	Autoscaling *EgressAutoscaling `json:"autoscaling,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget for egress pods.
+	// If nil, maxUnavailable is 1.
+	// +optional
+	PodDisruptionBudget *EgressPDBSpec `json:"podDisruptionBudget,omitempty"`
+
+	// TopologySpread configures how egress pods are spread across nodes and zones.
+	// The default constraints are not added if the pod template has its own
+	// topology spread constraints or pod anti-affinity.
+	// +optional
+	TopologySpread *EgressTopologySpread `json:"topologySpread,omitempty"`
+}
+
+// EgressPDBSpec defines the PodDisruptionBudget for egress pods.
+// Only one of MinAvailable and MaxUnavailable can be specified.
+type EgressPDBSpec struct {
+	// MinAvailable is the minimum number of available egress pods during disruptions.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the maximum number of unavailable egress pods during disruptions.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// EgressTopologySpread defines the default placement of egress pods.
+type EgressTopologySpread struct {
+	// Disabled disables the default topology spread constraints and pod anti-affinity.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// NodeWhenUnsatisfiable is the action for the constraint across nodes.
+	// Defaults to ScheduleAnyway.
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	NodeWhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"nodeWhenUnsatisfiable,omitempty"`
+
+	// ZoneWhenUnsatisfiable is the action for the constraint across zones.
+	// Defaults to ScheduleAnyway.
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	ZoneWhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"zoneWhenUnsatisfiable,omitempty"`
+}
+
+// EgressAutoscaling defines the autoscaling policy of egress pods.
+// The desired number of replicas is the largest of those calculated
+// for the specified targets.
+type EgressAutoscaling struct {
+	// MinReplicas is the lower limit of the number of replicas.
+	// Defaults to 1.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetClientsPerReplica is the target number of client pods per replica.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetClientsPerReplica *int32 `json:"targetClientsPerReplica,omitempty"`
+
+	// TargetBytesPerSecondPerReplica is the target throughput per replica.
+	// The throughput is the sum of bytes received and transmitted by the
+	// network interface through which NAT'ed packets go out.
+	// +optional
+	TargetBytesPerSecondPerReplica *resource.Quantity `json:"targetBytesPerSecondPerReplica,omitempty"`
+}
+
+// EgressDestination defines a destination network of Egress
+type EgressDestination struct {
+	// CIDR is an IP network in CIDR format.
+	CIDR string `json:"cidr"`
+
+	// NextHop is the IP address of the router to which egress pods send
+	// packets for CIDR instead of the default route.  The source address
+	// of the packets is translated to an address of the interface through
+	// which NextHop is reached.
+	// It must be of the same IP family as CIDR.
+	// +optional
+	NextHop string `json:"nextHop,omitempty"`
+}
+
+// EgressContainerName is the name of the container running egress-gw in egress pods.
+const EgressContainerName = "egress-gw"
+
+// EgressPodTemplate defines pod template for Egress
+//
+// This is almost the same as corev1.PodTemplate but is simplified to
+// workaround JSON patch issues.
+type EgressPodTemplate struct {
+	// Metadata defines optional labels and annotations
+	// +optional
+	Metadata `json:"metadata,omitempty"`
+
+	// Spec defines the pod template spec.
+	// +optional
+	Spec corev1.PodSpec `json:"spec,omitempty"`
+}
+
+// Metadata defines a simplified version of ObjectMeta.
+type Metadata struct {
+	// Annotations are optional annotations
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels are optional labels
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ClientDestinations returns the destination networks that client pods
+// route to egress pods.  Destinations included in another destination
+// to specify a next hop are omitted because they are routed anyway.
+func (es *EgressSpec) ClientDestinations() []string {
+	var dsts []string
+	for i, d := range es.Destinations {
+		if d.NextHop != "" && es.isSubnet(i) {
+			continue
+		}
+		dsts = append(dsts, d.CIDR)
+	}
+	return dsts
+}
+
+// isSubnet returns true if the i-th destination is strictly included in another destination.
+func (es *EgressSpec) isSubnet(i int) bool {
+	_, n, err := net.ParseCIDR(es.Destinations[i].CIDR)
+	if err != nil {
+		return false
+	}
+	ones, bits := n.Mask.Size()
+	for j, d := range es.Destinations {
+		if j == i {
+			continue
+		}
+		_, dst, err := net.ParseCIDR(d.CIDR)
+		if err != nil {
+			continue
+		}
+		dOnes, dBits := dst.Mask.Size()
+		if dBits == bits && dOnes < ones && dst.Contains(n.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (es *EgressSpec) validate() field.ErrorList {
+	var allErrs field.ErrorList
+	p := field.NewPath("spec")
+
+	pp := p.Child("destinations")
+	for i, d := range es.Destinations {
+		_, n, err := net.ParseCIDR(d.CIDR)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("cidr"), d.CIDR, err.Error()))
+			continue
+		}
+		if d.NextHop == "" {
+			continue
+		}
+		gw := net.ParseIP(d.NextHop)
+		if gw == nil {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("nextHop"), d.NextHop, "invalid IP address"))
+			continue
+		}
+		if (n.IP.To4() != nil) != (gw.To4() != nil) {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("nextHop"), d.NextHop, "IP family mismatch"))
+		}
+	}
+
+	if es.Strategy != nil {
+		switch es.Strategy.Type {
+		case appsv1.RecreateDeploymentStrategyType:
+		case appsv1.RollingUpdateDeploymentStrategyType:
+		default:
+			allErrs = append(allErrs, field.NotSupported(p.Child("strategy", "type"), es.Strategy.Type, []string{
+				string(appsv1.RecreateDeploymentStrategyType),
+				string(appsv1.RollingUpdateDeploymentStrategyType),
+			}))
+		}
+	}
+
+	if es.Interface != "" && !isValidInterfaceName(es.Interface) {
+		allErrs = append(allErrs, field.Invalid(p.Child("interface"), es.Interface, "invalid interface name"))
+	}
+
+	if es.NetworkAttachment != "" {
+		pp := p.Child("networkAttachment")
+		ns, name, found := strings.Cut(es.NetworkAttachment, "/")
+		if !found {
+			ns, name = "", ns
+		}
+		if found {
+			for _, msg := range apivalidation.ValidateNamespaceName(ns, false) {
+				allErrs = append(allErrs, field.Invalid(pp, es.NetworkAttachment, msg))
+			}
+		}
+		for _, msg := range apivalidation.NameIsDNSSubdomain(name, false) {
+			allErrs = append(allErrs, field.Invalid(pp, es.NetworkAttachment, msg))
+		}
+	}
+
+	if es.AllowedNamespaces != nil {
+		allErrs = append(allErrs, validation.ValidateLabelSelector(es.AllowedNamespaces,
+			validation.LabelSelectorValidationOptions{}, p.Child("allowedNamespaces"))...)
+	}
+
+	if as := es.Autoscaling; as != nil {
+		pp := p.Child("autoscaling")
+		if as.MaxReplicas < as.MinReplicas {
+			allErrs = append(allErrs, field.Invalid(pp.Child("maxReplicas"), as.MaxReplicas, "must be greater than or equal to minReplicas"))
+		}
+		if as.TargetClientsPerReplica == nil && as.TargetBytesPerSecondPerReplica == nil {
+			allErrs = append(allErrs, field.Required(pp, "at least one target must be specified"))
+		}
+		if as.TargetBytesPerSecondPerReplica != nil && as.TargetBytesPerSecondPerReplica.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(pp.Child("targetBytesPerSecondPerReplica"), as.TargetBytesPerSecondPerReplica.String(), "must be positive"))
+		}
+	}
+
+	if pdb := es.PodDisruptionBudget; pdb != nil {
+		pp := p.Child("podDisruptionBudget")
+		if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+			allErrs = append(allErrs, field.Forbidden(pp, "minAvailable and maxUnavailable cannot be both set"))
+		}
+		if pdb.MinAvailable != nil {
+			allErrs = append(allErrs, validateIntOrPercent(pdb.MinAvailable, pp.Child("minAvailable"))...)
+		}
+		if pdb.MaxUnavailable != nil {
+			allErrs = append(allErrs, validateIntOrPercent(pdb.MaxUnavailable, pp.Child("maxUnavailable"))...)
+		}
+	}
+
+	if es.Template != nil {
+		pp := p.Child("template", "metadata")
+		allErrs = append(allErrs, validation.ValidateLabels(es.Template.Labels, pp.Child("labels"))...)
+		pp = pp.Child("annotations")
+		for k := range es.Template.Annotations {
+			allErrs = append(allErrs, validation.ValidateLabelName(k, pp)...)
+		}
+
+		pp = p.Child("template", "spec", "containers")
+		for i := range es.Template.Spec.Containers {
+			c := &es.Template.Spec.Containers[i]
+			if c.Name == EgressContainerName {
+				allErrs = append(allErrs, validateEgressContainer(c, pp.Index(i))...)
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateEgressContainer rejects settings of the egress-gw container
+// which prevent egress-gw from working.
+// Unspecified fields are filled with the defaults by the controller.
+func validateEgressContainer(c *corev1.Container, p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	sc := c.SecurityContext
+	if sc != nil && sc.Privileged != nil && !*sc.Privileged && sc.Capabilities != nil {
+		pp := p.Child("securityContext", "capabilities")
+		if !hasCapability(sc.Capabilities.Add, "NET_ADMIN") {
+			allErrs = append(allErrs, field.Required(pp.Child("add"), "NET_ADMIN is required for unprivileged egress-gw"))
+		}
+		for j, d := range sc.Capabilities.Drop {
+			if d == "NET_ADMIN" || d == "CAP_NET_ADMIN" {
+				allErrs = append(allErrs, field.Forbidden(pp.Child("drop").Index(j), "NET_ADMIN must not be dropped"))
+			}
+		}
+	}
+
+	ports := make(map[string]bool)
+	for _, port := range c.Ports {
+		if port.Name != "" {
+			ports[port.Name] = true
+		}
+	}
+	for j, port := range c.Ports {
+		if (port.Name == "metrics" || port.Name == "health") && port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			allErrs = append(allErrs, field.NotSupported(p.Child("ports").Index(j).Child("protocol"), port.Protocol, []string{string(corev1.ProtocolTCP)}))
+		}
+	}
+
+	// the controller adds the "metrics" and "health" ports if missing.
+	ports["metrics"] = true
+	ports["health"] = true
+	probes := []struct {
+		name  string
+		probe *corev1.Probe
+	}{
+		{"livenessProbe", c.LivenessProbe},
+		{"readinessProbe", c.ReadinessProbe},
+	}
+	for _, pr := range probes {
+		name, probe := pr.name, pr.probe
+		if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Port.Type != intstr.String {
+			continue
+		}
+		if port := probe.HTTPGet.Port.StrVal; !ports[port] {
+			allErrs = append(allErrs, field.NotFound(p.Child(name, "httpGet", "port"), port))
+		}
+	}
+
+	return allErrs
+}
+
+func hasCapability(caps []corev1.Capability, name corev1.Capability) bool {
+	for _, c := range caps {
+		if c == name || c == "CAP_"+name || c == "ALL" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIntOrPercent validates a non-negative integer or a percentage up to 100%.
+func validateIntOrPercent(v *intstr.IntOrString, p *field.Path) field.ErrorList {
+	n, err := intstr.GetScaledValueFromIntOrPercent(v, 100, false)
+	if err != nil {
+		return field.ErrorList{field.Invalid(p, v.String(), err.Error())}
+	}
+	if n < 0 || (v.Type == intstr.String && n > 100) {
+		return field.ErrorList{field.Invalid(p, v.String(), "must be a non-negative integer or a percentage up to 100%")}
+	}
+	return nil
+}
+
+// validateCreate validates the spec of a new Egress.
+//
+// Destinations are checked more strictly than validate because they
+// are unchangeable and existing Egresses should remain updatable.
+func (es *EgressSpec) validateCreate() field.ErrorList {
+	allErrs := es.validate()
+	pp := field.NewPath("spec", "destinations")
+
+	dsts := make([]*net.IPNet, len(es.Destinations))
+	for i, d := range es.Destinations {
+		_, n, err := net.ParseCIDR(d.CIDR)
+		if err != nil {
+			continue
+		}
+		if r := reservedNetwork(n); r != nil {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("cidr"), d.CIDR, "included in the reserved network "+r.String()))
+		}
+		for j, dst := range dsts[:i] {
+			if dst == nil || !overlaps(n, dst) {
+				continue
+			}
+			// a subnet of another destination is allowed to specify the next hop.
+			ones, _ := n.Mask.Size()
+			dOnes, _ := dst.Mask.Size()
+			if ones > dOnes && d.NextHop != "" {
+				continue
+			}
+			if dOnes > ones && es.Destinations[j].NextHop != "" {
+				continue
+			}
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("cidr"), d.CIDR, "overlaps with "+es.Destinations[j].CIDR))
+		}
+		dsts[i] = n
+	}
+
+	return allErrs
+}
+
+// normalize rewrites destinations in the canonical CIDR format.
+func (es *EgressSpec) normalize() {
+	for i, d := range es.Destinations {
+		es.Destinations[i].CIDR = normalizeCIDR(d.CIDR)
+	}
+}
+
+// normalizeCIDR returns `s` in the canonical format, e.g. 10.0.0.0/8 for 10.0.0.1/8.
+// Invalid CIDRs are returned as is.
+func normalizeCIDR(s string) string {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return s
+	}
+	return n.String()
+}
+
+// reservedNetworks are networks that cannot be routed to egress pods.
+var reservedNetworks = []*net.IPNet{
+	mustParseCIDR("127.0.0.0/8"),
+	mustParseCIDR("169.254.0.0/16"),
+	mustParseCIDR("224.0.0.0/4"),
+	mustParseCIDR("255.255.255.255/32"),
+	mustParseCIDR("::1/128"),
+	mustParseCIDR("fe80::/10"),
+	mustParseCIDR("ff00::/8"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// reservedNetwork returns the reserved network that includes `n`, or nil.
+// Networks including a reserved network such as 0.0.0.0/0 are allowed.
+func reservedNetwork(n *net.IPNet) *net.IPNet {
+	ones, bits := n.Mask.Size()
+	for _, r := range reservedNetworks {
+		rOnes, rBits := r.Mask.Size()
+		if rBits == bits && rOnes <= ones && r.Contains(n.IP) {
+			return r
+		}
+	}
+	return nil
+}
+
+// overlaps returns true if `a` and `b` share some addresses.
+func overlaps(a, b *net.IPNet) bool {
+	if (a.IP.To4() != nil) != (b.IP.To4() != nil) {
+		return false
+	}
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// isValidInterfaceName checks the name in the same way as the Linux kernel.
+func isValidInterfaceName(name string) bool {
+	if len(name) > 15 || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, "/: \t\n")
+}
+
+func (es *EgressSpec) validateUpdate(old EgressSpec) field.ErrorList {
+	allErrs := es.validate()
+	p := field.NewPath("spec")
+
+	// old destinations may have been stored before normalization.
+	// The slice is shared with the old object, so normalize a copy.
+	old = *old.DeepCopy()
+	old.normalize()
+	if !reflect.DeepEqual(es.ClientDestinations(), old.ClientDestinations()) {
+		allErrs = append(allErrs, field.Forbidden(p.Child("destinations"), "unchangeable"))
+	}
+
+	return allErrs
+}
+
+// EgressStatus defines the observed state of Egress
+type EgressStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Replicas is copied from the underlying Deployment's status.availableReplicas.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is copied from the underlying Deployment's status.readyReplicas.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is a serialized label selector in string form.
+	Selector string `json:"selector,omitempty"`
+
+	// Conditions represent the latest available observations of the Egress.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Condition types of Egress
+const (
+	// EgressAvailable means that the Egress has the minimum number of available egress pods.
+	EgressAvailable = "Available"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:selectorpath=.status.selector,specpath=.spec.replicas,statuspath=.status.replicas
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.replicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Egress is the Schema for the egresses API
+type Egress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EgressSpec   `json:"spec,omitempty"`
+	Status EgressStatus `json:"status,omitempty"`
+}
+
+// IsNamespaceAllowed returns true if pods in `ns` can use the Egress.
+func (eg *Egress) IsNamespaceAllowed(ns *corev1.Namespace) (bool, error) {
+	if ns.Name == eg.Namespace || eg.Spec.AllowedNamespaces == nil {
+		return true, nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(eg.Spec.AllowedNamespaces)
+	if err != nil {
+		return false, err
+	}
+	return sel.Matches(labels.Set(ns.Labels)), nil
+}
+
+// +kubebuilder:object:root=true
+
+// EgressList contains a list of Egress
+type EgressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Egress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Egress{}, &EgressList{})
+}
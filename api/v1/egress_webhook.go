@@ -0,0 +1,225 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager setups the webhook for Egress.
+// Destinations overlapping with `clusterCIDRs` are warned.
+func (r *Egress) SetupWebhookWithManager(mgr ctrl.Manager, clusterCIDRs []*net.IPNet) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&egressValidator{client: mgr.GetClient(), clusterCIDRs: clusterCIDRs}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-egress-ysksuzuki-com-v1-egress,mutating=true,failurePolicy=fail,sideEffects=None,groups=egress.ysksuzuki.com,resources=egresses,verbs=create;update,versions=v1,name=megress.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &Egress{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Egress) Default() {
+	r.Spec.setDefaults()
+}
+
+func (es *EgressSpec) setDefaults() {
+	es.normalize()
+
+	tmpl := es.Template
+	if tmpl == nil {
+		return
+	}
+
+	for _, c := range tmpl.Spec.Containers {
+		if c.Name == EgressContainerName {
+			return
+		}
+	}
+	// the controller also puts the egress container first.
+	tmpl.Spec.Containers = append([]corev1.Container{{Name: EgressContainerName}}, tmpl.Spec.Containers...)
+}
+
+// +kubebuilder:webhook:path=/validate-egress-ysksuzuki-com-v1-egress,mutating=false,failurePolicy=fail,sideEffects=None,groups=egress.ysksuzuki.com,resources=egresses,verbs=create;update,versions=v1,name=vegress.kb.io,admissionReviewVersions=v1
+
+// egressValidator validates Egress.
+// In addition to the validation of the spec, it warns destinations
+// overlapping with other Egresses or the cluster's own networks.
+type egressValidator struct {
+	client       client.Reader
+	clusterCIDRs []*net.IPNet
+}
+
+var _ webhook.CustomValidator = &egressValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *egressValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	switch r := obj.(type) {
+	case *Egress:
+		errs := r.Spec.validateCreate()
+		nameErrs, err := v.validateName(ctx, r.Namespace, r.Name, "Egress "+r.Namespace+"/"+r.Name)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, nameErrs...)
+		if len(errs) > 0 {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Egress"}, r.Name, errs)
+		}
+		return v.warnings(ctx, "Egress "+r.Namespace+"/"+r.Name, &r.Spec)
+	case *ClusterEgress:
+		errs := r.Spec.validateCreate()
+		nameErrs, err := v.validateName(ctx, r.Spec.Namespace, r.ResourceName(), "ClusterEgress "+r.Name)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, nameErrs...)
+		if len(errs) > 0 {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "ClusterEgress"}, r.Name, errs)
+		}
+		return v.warnings(ctx, "ClusterEgress "+r.Name, &r.Spec.EgressSpec)
+	}
+	return nil, fmt.Errorf("unexpected object type %T", obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *egressValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	switch r := newObj.(type) {
+	case *Egress:
+		errs := r.Spec.validateUpdate(oldObj.(*Egress).Spec)
+		if len(errs) > 0 {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Egress"}, r.Name, errs)
+		}
+		return v.warnings(ctx, "Egress "+r.Namespace+"/"+r.Name, &r.Spec)
+	case *ClusterEgress:
+		errs := r.Spec.validateUpdate(oldObj.(*ClusterEgress).Spec)
+		if len(errs) > 0 {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "ClusterEgress"}, r.Name, errs)
+		}
+		return v.warnings(ctx, "ClusterEgress "+r.Name, &r.Spec.EgressSpec)
+	}
+	return nil, fmt.Errorf("unexpected object type %T", newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *egressValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
+
+// validateName rejects an object whose resources named `name` in `ns` would
+// collide with those of another Egress or ClusterEgress.
+// `self` identifies the object being validated to exclude it.
+func (v *egressValidator) validateName(ctx context.Context, ns, name, self string) (field.ErrorList, error) {
+	var allErrs field.ErrorList
+	p := field.NewPath("metadata", "name")
+
+	eg := &Egress{}
+	err := v.client.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, eg)
+	switch {
+	case err == nil:
+		if k := "Egress " + ns + "/" + name; k != self {
+			allErrs = append(allErrs, field.Forbidden(p, "resources in namespace "+ns+" collide with those of "+k))
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, err
+	}
+
+	cegresses := &ClusterEgressList{}
+	if err := v.client.List(ctx, cegresses); err != nil {
+		return nil, err
+	}
+	for i := range cegresses.Items {
+		ce := &cegresses.Items[i]
+		if k := "ClusterEgress " + ce.Name; k != self && ce.Spec.Namespace == ns && ce.ResourceName() == name {
+			allErrs = append(allErrs, field.Forbidden(p, "resources in namespace "+ns+" collide with those of "+k))
+		}
+	}
+	return allErrs, nil
+}
+
+// warnings returns warnings for destinations of `spec` overlapping with
+// the cluster networks or destinations of other Egresses and ClusterEgresses.
+// `self` identifies the object being validated to exclude it.
+func (v *egressValidator) warnings(ctx context.Context, self string, spec *EgressSpec) (admission.Warnings, error) {
+	var warnings admission.Warnings
+
+	dsts := spec.ClientDestinations()
+	for _, d := range dsts {
+		_, dst, _ := net.ParseCIDR(d)
+		for _, n := range v.clusterCIDRs {
+			if overlaps(dst, n) {
+				warnings = append(warnings, fmt.Sprintf("destination %s overlaps with the cluster network %s", d, n))
+			}
+		}
+	}
+
+	others := make(map[string]*EgressSpec)
+	egresses := &EgressList{}
+	if err := v.client.List(ctx, egresses); err != nil {
+		return nil, err
+	}
+	for i := range egresses.Items {
+		eg := &egresses.Items[i]
+		others["Egress "+eg.Namespace+"/"+eg.Name] = &eg.Spec
+	}
+	cegresses := &ClusterEgressList{}
+	if err := v.client.List(ctx, cegresses); err != nil {
+		return nil, err
+	}
+	for i := range cegresses.Items {
+		ce := &cegresses.Items[i]
+		others["ClusterEgress "+ce.Name] = &ce.Spec.EgressSpec
+	}
+
+	keys := make([]string, 0, len(others))
+	for k := range others {
+		if k != self {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, d := range dsts {
+			_, dst, _ := net.ParseCIDR(d)
+			for _, od := range others[k].ClientDestinations() {
+				_, other, err := net.ParseCIDR(od)
+				if err != nil {
+					continue
+				}
+				if overlaps(dst, other) {
+					warnings = append(warnings, fmt.Sprintf("destination %s overlaps with %s of %s", d, od, k))
+				}
+			}
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,488 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func makeEgress() *Egress {
+	return &Egress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: EgressSpec{
+			Destinations: []EgressDestination{{CIDR: "10.2.0.0/16"}},
+			Replicas:     1,
+		},
+	}
+}
+
+var _ = Describe("Egress Webhook", func() {
+	ctx := context.TODO()
+
+	BeforeEach(func() {
+		r := &Egress{}
+		r.Name = "test"
+		r.Namespace = "default"
+		err := k8sClient.Delete(ctx, r)
+		if err == nil {
+			return
+		}
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should create egress with the sane defaults", func() {
+		r := makeEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "fd02::/120"})
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Spec.Replicas).To(BeNumerically("==", 1))
+		Expect(string(r.Spec.SessionAffinity)).To(Equal("ClientIP"))
+	})
+
+	It("should put the egress container first in the template", func() {
+		r := makeEgress()
+		r.Spec.Template = &EgressPodTemplate{}
+		r.Spec.Template.Spec.Containers = []corev1.Container{{Name: "sidecar", Image: "nginx"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Spec.Template.Spec.Containers).To(HaveLen(2))
+		Expect(r.Spec.Template.Spec.Containers[0].Name).To(Equal(EgressContainerName))
+		Expect(r.Spec.Template.Spec.Containers[1].Name).To(Equal("sidecar"))
+	})
+
+	It("should deny empty destinations", func() {
+		r := makeEgress()
+		r.Spec.Destinations = nil
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny bad subnets", func() {
+		r := makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "127.0.0.1"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "a.b.c.d/20"})
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny invalid replicas", func() {
+		r := makeEgress()
+		r.Spec.Replicas = -1
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny invalid deployment strategy", func() {
+		r := makeEgress()
+		r.Spec.Strategy = &appsv1.DeploymentStrategy{
+			Type: appsv1.DeploymentStrategyType("hoge"),
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow valid deployment strategy", func() {
+		r := makeEgress()
+		r.Spec.Strategy = &appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny bad pod templates", func() {
+		r := makeEgress()
+		r.Spec.Template = &EgressPodTemplate{
+			Metadata: Metadata{
+				Annotations: map[string]string{"bad bad": "aaa"},
+			},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Template = &EgressPodTemplate{
+			Metadata: Metadata{
+				Labels: map[string]string{"good": "bad value", "name": "foo"},
+			},
+		}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow valid pod templates", func() {
+		r := makeEgress()
+		r.Spec.Template = &EgressPodTemplate{
+			Metadata: Metadata{
+				Annotations: map[string]string{"good": "aaa bbb"},
+				Labels:      map[string]string{"good": "good"},
+			},
+			Spec: corev1.PodSpec{
+				Tolerations: []corev1.Toleration{
+					{
+						Operator: corev1.TolerationOpExists,
+					},
+				},
+			},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny invalid session affinity", func() {
+		r := makeEgress()
+		r.Spec.SessionAffinity = corev1.ServiceAffinity("hoge")
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow valid session affinity", func() {
+		r := makeEgress()
+		r.Spec.SessionAffinity = corev1.ServiceAffinityNone
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny invalid interface names", func() {
+		r := makeEgress()
+		r.Spec.Interface = "eth0/1"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Interface = "averyveryverylongname"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow valid interface names", func() {
+		r := makeEgress()
+		r.Spec.Interface = "net1"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate network attachment", func() {
+		r := makeEgress()
+		r.Spec.NetworkAttachment = "Invalid_Name"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.NetworkAttachment = "a/b/c"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.NetworkAttachment = "uplink"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r = makeEgress()
+		r.Name = "test2"
+		r.Spec.NetworkAttachment = "kube-system/uplink"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Delete(ctx, r)).To(Succeed())
+	})
+
+	It("should normalize destinations", func() {
+		r := makeEgress()
+		r.Spec.Destinations = []EgressDestination{
+			{CIDR: "10.2.3.4/16"},
+			{CIDR: "fd02::1/120"},
+			{CIDR: "10.2.1.1/24", NextHop: "192.0.2.1"},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Spec.Destinations).To(Equal([]EgressDestination{
+			{CIDR: "10.2.0.0/16"},
+			{CIDR: "fd02::/120"},
+			{CIDR: "10.2.1.0/24", NextHop: "192.0.2.1"},
+		}))
+	})
+
+	It("should deny reserved destinations", func() {
+		for _, d := range []string{"127.0.0.0/8", "127.0.0.1/32", "169.254.169.254/32", "224.0.0.0/24", "::1/128", "fe80::/64", "ff02::/16"} {
+			r := makeEgress()
+			r.Spec.Destinations = []EgressDestination{{CIDR: d}}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), d)
+		}
+
+		r := makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "0.0.0.0/0"}, {CIDR: "::/0"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny overlapping destinations", func() {
+		r := makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "10.2.0.0/16"}, {CIDR: "10.2.0.0/16"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "10.2.0.0/16"}, {CIDR: "10.2.3.0/24"}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "10.2.0.0/16", NextHop: "192.0.2.1"}, {CIDR: "10.2.3.0/24"}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = []EgressDestination{{CIDR: "10.2.0.0/16"}, {CIDR: "10.3.0.0/16"}, {CIDR: "fd02::/120"}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should warn overlapping destinations", func() {
+		r := makeEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, clusterCIDR, _ := net.ParseCIDR("10.2.128.0/17")
+		v := &egressValidator{client: k8sClient, clusterCIDRs: []*net.IPNet{clusterCIDR}}
+
+		other := makeEgress()
+		other.Name = "test2"
+		other.Spec.Destinations = []EgressDestination{{CIDR: "10.0.0.0/8"}, {CIDR: "10.3.0.0/16"}}
+		warnings, err := v.ValidateCreate(ctx, other)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(
+			"destination 10.0.0.0/8 overlaps with the cluster network 10.2.128.0/17",
+			"destination 10.0.0.0/8 overlaps with 10.2.0.0/16 of Egress default/test",
+		))
+
+		warnings, err = v.ValidateUpdate(ctx, r, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(
+			"destination 10.2.0.0/16 overlaps with the cluster network 10.2.128.0/17",
+		))
+	})
+
+	It("should validate next hops", func() {
+		r := makeEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.2.1.0/24", NextHop: "fd02::1"})
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.2.1.0/24", NextHop: "192.0.2"})
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Destinations = []EgressDestination{
+			{CIDR: "10.2.0.0/16", NextHop: "192.0.2.1"},
+			{CIDR: "10.2.1.0/24", NextHop: "192.0.2.2"},
+			{CIDR: "10.3.0.0/24", NextHop: "192.0.2.1"},
+		}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Spec.ClientDestinations()).To(Equal([]string{"10.2.0.0/16", "10.3.0.0/24"}))
+	})
+
+	It("should validate allowed namespaces", func() {
+		r := makeEgress()
+		r.Spec.AllowedNamespaces = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn},
+			},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.AllowedNamespaces = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "a"},
+		}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate autoscaling", func() {
+		r := makeEgress()
+		r.Spec.Autoscaling = &EgressAutoscaling{MinReplicas: 1, MaxReplicas: 3}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Autoscaling = &EgressAutoscaling{MinReplicas: 3, MaxReplicas: 2, TargetClientsPerReplica: pointer.Int32(10)}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Autoscaling = &EgressAutoscaling{MaxReplicas: 3, TargetBytesPerSecondPerReplica: resource.NewQuantity(0, resource.BinarySI)}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.Autoscaling = &EgressAutoscaling{MaxReplicas: 3, TargetBytesPerSecondPerReplica: resource.NewQuantity(100<<20, resource.BinarySI)}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Spec.Autoscaling.MinReplicas).To(BeNumerically("==", 1))
+	})
+
+	It("should validate pod disruption budget", func() {
+		r := makeEgress()
+		minAvailable := intstr.FromInt(1)
+		maxUnavailable := intstr.FromString("50%")
+		r.Spec.PodDisruptionBudget = &EgressPDBSpec{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		invalid := intstr.FromString("150%")
+		r.Spec.PodDisruptionBudget = &EgressPDBSpec{MaxUnavailable: &invalid}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.PodDisruptionBudget = &EgressPDBSpec{MaxUnavailable: &maxUnavailable}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate topology spread", func() {
+		r := makeEgress()
+		r.Spec.TopologySpread = &EgressTopologySpread{NodeWhenUnsatisfiable: "Never"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeEgress()
+		r.Spec.TopologySpread = &EgressTopologySpread{NodeWhenUnsatisfiable: corev1.DoNotSchedule}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate the egress-gw container", func() {
+		unprivileged := func(caps *corev1.Capabilities) *Egress {
+			r := makeEgress()
+			r.Spec.Template = &EgressPodTemplate{}
+			r.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "egress-gw",
+				SecurityContext: &corev1.SecurityContext{
+					Privileged:   pointer.Bool(false),
+					Capabilities: caps,
+				},
+			}}
+			return r
+		}
+
+		r := unprivileged(&corev1.Capabilities{Add: []corev1.Capability{"NET_RAW"}})
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = unprivileged(&corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}, Drop: []corev1.Capability{"NET_ADMIN"}})
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = unprivileged(&corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}})
+		r.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "health", ContainerPort: 8081, Protocol: corev1.ProtocolUDP}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = unprivileged(&corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}})
+		r.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("probe")}},
+		}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = unprivileged(&corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}, Drop: []corev1.Capability{"ALL"}})
+		r.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{PeriodSeconds: 30}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny updating destinations", func() {
+		r := makeEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.10.0.0/24"})
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow updating next hops", func() {
+		r := makeEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Destinations[0].NextHop = "192.0.2.1"
+		r.Spec.Destinations = append(r.Spec.Destinations, EgressDestination{CIDR: "10.2.1.0/24", NextHop: "192.0.2.2"})
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not modify the old object on update", func() {
+		old := makeEgress()
+		old.Spec.Destinations = []EgressDestination{{CIDR: "10.2.3.4/16"}}
+		r := old.DeepCopy()
+		r.Spec.Destinations[0].CIDR = "10.2.0.0/16"
+
+		errs := r.Spec.validateUpdate(old.Spec)
+		Expect(errs).To(BeEmpty())
+		Expect(old.Spec.Destinations[0].CIDR).To(Equal("10.2.3.4/16"))
+	})
+
+	It("should deny invalid fields on update", func() {
+		r := makeEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Replicas = -1
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow updating other fields", func() {
+		r := makeEgress()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Replicas = 10
+		r.Spec.SessionAffinity = corev1.ServiceAffinityNone
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
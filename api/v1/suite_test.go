@@ -1,4 +1,4 @@
-package v1beta1
+package v1
 
 import (
 	"context"
@@ -88,7 +88,10 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&Egress{}).SetupWebhookWithManager(mgr)
+	err = (&Egress{}).SetupWebhookWithManager(mgr, nil)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&ClusterEgress{}).SetupWebhookWithManager(mgr, nil)
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
@@ -0,0 +1,193 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &Egress{}
+
+// ConvertTo converts this Egress to the Hub version (v1).
+//
+// Next hops for destinations are merged into the destinations.
+// Next hops for subnets of destinations become additional destinations.
+func (src *Egress) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*egressv1.Egress)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.Destinations = make([]egressv1.EgressDestination, 0, len(src.Spec.Destinations))
+	used := make([]bool, len(src.Spec.NextHops))
+	for _, d := range src.Spec.Destinations {
+		ed := egressv1.EgressDestination{CIDR: d}
+		for i, nh := range src.Spec.NextHops {
+			if !sameCIDR(nh.Destination, d) {
+				continue
+			}
+			// only the first next hop is effective for the same destination.
+			if ed.NextHop == "" {
+				ed.NextHop = nh.NextHop
+			}
+			used[i] = true
+		}
+		dst.Spec.Destinations = append(dst.Spec.Destinations, ed)
+	}
+	for i, nh := range src.Spec.NextHops {
+		if used[i] {
+			continue
+		}
+		dst.Spec.Destinations = append(dst.Spec.Destinations, egressv1.EgressDestination{
+			CIDR:    nh.Destination,
+			NextHop: nh.NextHop,
+		})
+	}
+
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Strategy = src.Spec.Strategy
+	if src.Spec.Template != nil {
+		dst.Spec.Template = &egressv1.EgressPodTemplate{
+			Metadata: egressv1.Metadata{
+				Annotations: src.Spec.Template.Annotations,
+				Labels:      src.Spec.Template.Labels,
+			},
+			Spec: src.Spec.Template.Spec,
+		}
+	}
+	dst.Spec.SessionAffinity = src.Spec.SessionAffinity
+	dst.Spec.SessionAffinityConfig = src.Spec.SessionAffinityConfig
+	dst.Spec.Interface = src.Spec.Interface
+	dst.Spec.NetworkAttachment = src.Spec.NetworkAttachment
+	dst.Spec.AllowedNamespaces = src.Spec.AllowedNamespaces
+	dst.Spec.Autoscaling = nil
+	if as := src.Spec.Autoscaling; as != nil {
+		dst.Spec.Autoscaling = &egressv1.EgressAutoscaling{
+			MinReplicas:                    as.MinReplicas,
+			MaxReplicas:                    as.MaxReplicas,
+			TargetClientsPerReplica:        as.TargetClientsPerReplica,
+			TargetBytesPerSecondPerReplica: as.TargetBytesPerSecondPerReplica,
+		}
+	}
+	dst.Spec.PodDisruptionBudget = nil
+	if pdb := src.Spec.PodDisruptionBudget; pdb != nil {
+		dst.Spec.PodDisruptionBudget = &egressv1.EgressPDBSpec{
+			MinAvailable:   pdb.MinAvailable,
+			MaxUnavailable: pdb.MaxUnavailable,
+		}
+	}
+	dst.Spec.TopologySpread = nil
+	if ts := src.Spec.TopologySpread; ts != nil {
+		dst.Spec.TopologySpread = &egressv1.EgressTopologySpread{
+			Disabled:              ts.Disabled,
+			NodeWhenUnsatisfiable: ts.NodeWhenUnsatisfiable,
+			ZoneWhenUnsatisfiable: ts.ZoneWhenUnsatisfiable,
+		}
+	}
+
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Conditions = src.Status.Conditions
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *Egress) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*egressv1.Egress)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.Destinations = src.Spec.ClientDestinations()
+	dst.Spec.NextHops = nil
+	for _, d := range src.Spec.Destinations {
+		if d.NextHop == "" {
+			continue
+		}
+		dst.Spec.NextHops = append(dst.Spec.NextHops, EgressNextHop{
+			Destination: d.CIDR,
+			NextHop:     d.NextHop,
+		})
+	}
+
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Strategy = src.Spec.Strategy
+	dst.Spec.Template = nil
+	if src.Spec.Template != nil {
+		dst.Spec.Template = &EgressPodTemplate{
+			Metadata: Metadata{
+				Annotations: src.Spec.Template.Annotations,
+				Labels:      src.Spec.Template.Labels,
+			},
+			Spec: src.Spec.Template.Spec,
+		}
+	}
+	dst.Spec.SessionAffinity = src.Spec.SessionAffinity
+	dst.Spec.SessionAffinityConfig = src.Spec.SessionAffinityConfig
+	dst.Spec.Interface = src.Spec.Interface
+	dst.Spec.NetworkAttachment = src.Spec.NetworkAttachment
+	dst.Spec.AllowedNamespaces = src.Spec.AllowedNamespaces
+	dst.Spec.Autoscaling = nil
+	if as := src.Spec.Autoscaling; as != nil {
+		dst.Spec.Autoscaling = &EgressAutoscaling{
+			MinReplicas:                    as.MinReplicas,
+			MaxReplicas:                    as.MaxReplicas,
+			TargetClientsPerReplica:        as.TargetClientsPerReplica,
+			TargetBytesPerSecondPerReplica: as.TargetBytesPerSecondPerReplica,
+		}
+	}
+	dst.Spec.PodDisruptionBudget = nil
+	if pdb := src.Spec.PodDisruptionBudget; pdb != nil {
+		dst.Spec.PodDisruptionBudget = &EgressPDBSpec{
+			MinAvailable:   pdb.MinAvailable,
+			MaxUnavailable: pdb.MaxUnavailable,
+		}
+	}
+	dst.Spec.TopologySpread = nil
+	if ts := src.Spec.TopologySpread; ts != nil {
+		dst.Spec.TopologySpread = &EgressTopologySpread{
+			Disabled:              ts.Disabled,
+			NodeWhenUnsatisfiable: ts.NodeWhenUnsatisfiable,
+			ZoneWhenUnsatisfiable: ts.ZoneWhenUnsatisfiable,
+		}
+	}
+
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Conditions = src.Status.Conditions
+
+	return nil
+}
+
+// sameCIDR returns true if `a` and `b` represent the same network.
+func sameCIDR(a, b string) bool {
+	_, na, err := net.ParseCIDR(a)
+	if err != nil {
+		return a == b
+	}
+	_, nb, err := net.ParseCIDR(b)
+	if err != nil {
+		return false
+	}
+	return na.String() == nb.String()
+}
@@ -0,0 +1,170 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func testEgressBeta() *Egress {
+	minAvailable := intstr.FromString("50%")
+	return &Egress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "egress",
+			Labels:    map[string]string{"foo": "bar"},
+		},
+		Spec: EgressSpec{
+			Destinations: []string{"10.0.0.0/8", "192.168.0.0/16", "fd02::/64"},
+			NextHops: []EgressNextHop{
+				{Destination: "192.168.0.0/16", NextHop: "192.0.2.1"},
+				{Destination: "10.1.0.0/16", NextHop: "192.0.2.2"},
+				{Destination: "fd02::/112", NextHop: "fd10::1"},
+			},
+			Replicas: 3,
+			Strategy: &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: &EgressPodTemplate{
+				Metadata: Metadata{
+					Annotations: map[string]string{"a": "b"},
+					Labels:      map[string]string{"c": "d"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "egress-gw"}},
+				},
+			},
+			SessionAffinity:   corev1.ServiceAffinityClientIP,
+			Interface:         "net1",
+			NetworkAttachment: "kube-system/uplink",
+			AllowedNamespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Autoscaling: &EgressAutoscaling{
+				MinReplicas:                    2,
+				MaxReplicas:                    5,
+				TargetClientsPerReplica:        pointer.Int32(10),
+				TargetBytesPerSecondPerReplica: resource.NewQuantity(100<<20, resource.BinarySI),
+			},
+			PodDisruptionBudget: &EgressPDBSpec{
+				MinAvailable: &minAvailable,
+			},
+			TopologySpread: &EgressTopologySpread{
+				ZoneWhenUnsatisfiable: corev1.DoNotSchedule,
+			},
+		},
+		Status: EgressStatus{
+			ObservedGeneration: 2,
+			Replicas:           3,
+			ReadyReplicas:      2,
+			Selector:           "app=egress",
+			Conditions: []metav1.Condition{{
+				Type:               egressv1.EgressAvailable,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 2,
+				LastTransitionTime: metav1.NewTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+				Reason:             "MinimumReplicasAvailable",
+				Message:            "2 of 3 replicas are available",
+			}},
+		},
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	src := testEgressBeta()
+	dst := &egressv1.Egress{}
+	if err := src.ConvertTo(dst); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []egressv1.EgressDestination{
+		{CIDR: "10.0.0.0/8"},
+		{CIDR: "192.168.0.0/16", NextHop: "192.0.2.1"},
+		{CIDR: "fd02::/64"},
+		{CIDR: "10.1.0.0/16", NextHop: "192.0.2.2"},
+		{CIDR: "fd02::/112", NextHop: "fd10::1"},
+	}
+	if !reflect.DeepEqual(dst.Spec.Destinations, expected) {
+		t.Errorf("unexpected destinations: %+v", dst.Spec.Destinations)
+	}
+	if !reflect.DeepEqual(dst.Spec.ClientDestinations(), src.Spec.Destinations) {
+		t.Errorf("unexpected client destinations: %v", dst.Spec.ClientDestinations())
+	}
+	if dst.Spec.Template == nil || dst.Spec.Template.Labels["c"] != "d" {
+		t.Errorf("template is not converted: %+v", dst.Spec.Template)
+	}
+	if dst.Status.Replicas != 3 || dst.Status.Selector != "app=egress" {
+		t.Errorf("status is not converted: %+v", dst.Status)
+	}
+	if dst.Status.ObservedGeneration != 2 || dst.Status.ReadyReplicas != 2 || len(dst.Status.Conditions) != 1 {
+		t.Errorf("status is not converted: %+v", dst.Status)
+	}
+}
+
+func TestRoundTripBeta(t *testing.T) {
+	testCases := map[string]func(*Egress){
+		"full":    func(*Egress) {},
+		"minimal": func(eg *Egress) { eg.Spec = EgressSpec{Destinations: []string{"0.0.0.0/0"}, Replicas: 1} },
+		"next hops for destinations only": func(eg *Egress) {
+			eg.Spec.NextHops = []EgressNextHop{{Destination: "10.0.0.0/8", NextHop: "192.0.2.1"}}
+		},
+	}
+
+	for name, modify := range testCases {
+		t.Run(name, func(t *testing.T) {
+			src := testEgressBeta()
+			modify(src)
+
+			hub := &egressv1.Egress{}
+			if err := src.ConvertTo(hub); err != nil {
+				t.Fatal(err)
+			}
+			dst := &Egress{}
+			if err := dst.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(src, dst) {
+				t.Errorf("round trip failed:\n%+v\n%+v", src, dst)
+			}
+		})
+	}
+}
+
+func TestRoundTripHub(t *testing.T) {
+	src := &egressv1.Egress{}
+	if err := testEgressBeta().ConvertTo(src); err != nil {
+		t.Fatal(err)
+	}
+	src.Status = egressv1.EgressStatus{
+		ObservedGeneration: 5,
+		Replicas:           3,
+		ReadyReplicas:      3,
+		Selector:           "app=egress",
+		Conditions: []metav1.Condition{{
+			Type:               egressv1.EgressAvailable,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 5,
+			LastTransitionTime: metav1.NewTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+			Reason:             "MinimumReplicasUnavailable",
+			Message:            "0 of 3 replicas are available",
+		}},
+	}
+
+	beta := &Egress{}
+	if err := beta.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	dst := &egressv1.Egress{}
+	if err := beta.ConvertTo(dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(src, dst) {
+		t.Errorf("round trip failed:\n%+v\n%+v", src, dst)
+	}
+}
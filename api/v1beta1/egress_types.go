@@ -17,14 +17,11 @@ limitations under the License.
 package v1beta1
 
 import (
-	"net"
-	"reflect"
-
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/validation"
-	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -39,6 +36,13 @@ type EgressSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	Destinations []string `json:"destinations"`
 
+	// NextHops is an optional list of next hops for destinations.
+	// Packets to the destination are routed to the next hop in egress pods
+	// instead of the default route.  Each destination must be one of or be
+	// included in Destinations.
+	// +optional
+	NextHops []EgressNextHop `json:"nextHops,omitempty"`
+
 	// Replicas is the desired number of egress (SNAT) pods.
 	// Defaults to 1.
 	// +kubebuilder:default=1
@@ -52,7 +56,7 @@ type EgressSpec struct {
 	Strategy *appsv1.DeploymentStrategy `json:"strategy,omitempty"`
 
 	// Template is an optional template for egress pods.
-	// A container named "egress" is special.  It is the main container of
+	// A container named "egress-gw" is special.  It is the main container of
 	// egress pods and usually is not meant to be modified.
 	// +optional
 	Template *EgressPodTemplate `json:"template,omitempty"`
@@ -69,6 +73,103 @@ type EgressSpec struct {
 	// Ref. https://pkg.go.dev/k8s.io/api/core/v1?tab=doc#ServiceSpec
 	// +optional
 	SessionAffinityConfig *corev1.SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
+
+	// Interface is the name of the network interface of egress pods
+	// through which NAT'ed packets go out.
+	// If empty, the interface of the default route is used.
+	// +kubebuilder:validation:MaxLength=15
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// NetworkAttachment is a reference to a NetworkAttachmentDefinition of
+	// Multus in "name" or "namespace/name" format.
+	// If given, egress pods get a secondary interface attached to the network,
+	// and NAT'ed packets go out of the interface through the gateway found in
+	// the network-status annotation of the pod.
+	// The networks annotation in the pod template is overwritten.
+	// +optional
+	NetworkAttachment string `json:"networkAttachment,omitempty"`
+
+	// AllowedNamespaces selects namespaces whose pods can use this Egress.
+	// Pods in the same namespace as the Egress are always allowed.
+	// If nil, pods in any namespace can use this Egress.
+	// To allow only the same namespace, specify a selector that matches
+	// no namespaces such as `{matchLabels: {kubernetes.io/metadata.name: ""}}`.
+	// +optional
+	AllowedNamespaces *metav1.LabelSelector `json:"allowedNamespaces,omitempty"`
+
+	// Autoscaling lets the controller scale egress pods by the number of
+	// client pods and the throughput of egress pods.
+	// +optional
+	Autoscaling *EgressAutoscaling `json:"autoscaling,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget for egress pods.
+	// +optional
+	PodDisruptionBudget *EgressPDBSpec `json:"podDisruptionBudget,omitempty"`
+
+	// TopologySpread configures how egress pods are spread across nodes and zones.
+	// +optional
+	TopologySpread *EgressTopologySpread `json:"topologySpread,omitempty"`
+}
+
+// EgressPDBSpec defines the PodDisruptionBudget for egress pods.
+type EgressPDBSpec struct {
+	// MinAvailable is the minimum number of available egress pods during disruptions.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the maximum number of unavailable egress pods during disruptions.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// EgressTopologySpread defines the default placement of egress pods.
+type EgressTopologySpread struct {
+	// Disabled disables the default topology spread constraints and pod anti-affinity.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// NodeWhenUnsatisfiable is the action for the constraint across nodes.
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	NodeWhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"nodeWhenUnsatisfiable,omitempty"`
+
+	// ZoneWhenUnsatisfiable is the action for the constraint across zones.
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	ZoneWhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"zoneWhenUnsatisfiable,omitempty"`
+}
+
+// EgressAutoscaling defines the autoscaling policy of egress pods.
+type EgressAutoscaling struct {
+	// MinReplicas is the lower limit of the number of replicas.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetClientsPerReplica is the target number of client pods per replica.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetClientsPerReplica *int32 `json:"targetClientsPerReplica,omitempty"`
+
+	// TargetBytesPerSecondPerReplica is the target throughput per replica.
+	// +optional
+	TargetBytesPerSecondPerReplica *resource.Quantity `json:"targetBytesPerSecondPerReplica,omitempty"`
+}
+
+// EgressNextHop defines the next hop for a destination
+type EgressNextHop struct {
+	// Destination is an IP network in CIDR format.
+	Destination string `json:"destination"`
+
+	// NextHop is the IP address of the next hop router.
+	// It must be of the same IP family as Destination.
+	NextHop string `json:"nextHop"`
 }
 
 // EgressPodTemplate defines pod template for Egress
@@ -96,64 +197,31 @@ type Metadata struct {
 	Labels map[string]string `json:"labels,omitempty"`
 }
 
-func (es *EgressSpec) validate() field.ErrorList {
-	var allErrs field.ErrorList
-	p := field.NewPath("spec")
-
-	pp := p.Child("destinations")
-	for i, na := range es.Destinations {
-		_, _, err := net.ParseCIDR(na)
-		if err != nil {
-			allErrs = append(allErrs, field.Invalid(pp.Index(i), na, err.Error()))
-		}
-	}
-
-	if es.Strategy != nil {
-		switch es.Strategy.Type {
-		case appsv1.RecreateDeploymentStrategyType:
-		case appsv1.RollingUpdateDeploymentStrategyType:
-		default:
-			allErrs = append(allErrs, field.NotSupported(p.Child("strategy", "type"), es.Strategy.Type, []string{
-				string(appsv1.RecreateDeploymentStrategyType),
-				string(appsv1.RollingUpdateDeploymentStrategyType),
-			}))
-		}
-	}
-
-	if es.Template != nil {
-		pp := p.Child("template", "metadata")
-		allErrs = append(allErrs, validation.ValidateLabels(es.Template.Labels, pp.Child("labels"))...)
-		pp = pp.Child("annotations")
-		for k := range es.Template.Annotations {
-			allErrs = append(allErrs, validation.ValidateLabelName(k, pp)...)
-		}
-	}
-
-	return allErrs
-}
-
-func (es *EgressSpec) validateUpdate(old EgressSpec) field.ErrorList {
-	allErrs := es.validate()
-	p := field.NewPath("spec")
-
-	if !reflect.DeepEqual(es.Destinations, old.Destinations) {
-		allErrs = append(allErrs, field.Forbidden(p.Child("destinations"), "unchangeable"))
-	}
-
-	return allErrs
-}
-
 // EgressStatus defines the observed state of Egress
 type EgressStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Replicas is copied from the underlying Deployment's status.replicas.
 	// +optional
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// ReadyReplicas is copied from the underlying Deployment's status.readyReplicas.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
 	// Selector is a serialized label selector in string form.
 	Selector string `json:"selector,omitempty"`
+
+	// Conditions represent the latest available observations of the Egress.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -24,7 +24,9 @@ package v1beta1
 import (
 	"k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -33,7 +35,7 @@ func (in *Egress) DeepCopyInto(out *Egress) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Egress.
@@ -54,6 +56,31 @@ func (in *Egress) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressAutoscaling) DeepCopyInto(out *EgressAutoscaling) {
+	*out = *in
+	if in.TargetClientsPerReplica != nil {
+		in, out := &in.TargetClientsPerReplica, &out.TargetClientsPerReplica
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetBytesPerSecondPerReplica != nil {
+		in, out := &in.TargetBytesPerSecondPerReplica, &out.TargetBytesPerSecondPerReplica
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressAutoscaling.
+func (in *EgressAutoscaling) DeepCopy() *EgressAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressList) DeepCopyInto(out *EgressList) {
 	*out = *in
@@ -86,6 +113,46 @@ func (in *EgressList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressNextHop) DeepCopyInto(out *EgressNextHop) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressNextHop.
+func (in *EgressNextHop) DeepCopy() *EgressNextHop {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressNextHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressPDBSpec) DeepCopyInto(out *EgressPDBSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressPDBSpec.
+func (in *EgressPDBSpec) DeepCopy() *EgressPDBSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressPDBSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressPodTemplate) DeepCopyInto(out *EgressPodTemplate) {
 	*out = *in
@@ -111,6 +178,11 @@ func (in *EgressSpec) DeepCopyInto(out *EgressSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NextHops != nil {
+		in, out := &in.NextHops, &out.NextHops
+		*out = make([]EgressNextHop, len(*in))
+		copy(*out, *in)
+	}
 	if in.Strategy != nil {
 		in, out := &in.Strategy, &out.Strategy
 		*out = new(v1.DeploymentStrategy)
@@ -126,6 +198,26 @@ func (in *EgressSpec) DeepCopyInto(out *EgressSpec) {
 		*out = new(corev1.SessionAffinityConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(EgressAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(EgressPDBSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(EgressTopologySpread)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressSpec.
@@ -141,6 +233,13 @@ func (in *EgressSpec) DeepCopy() *EgressSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressStatus) DeepCopyInto(out *EgressStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressStatus.
@@ -153,6 +252,21 @@ func (in *EgressStatus) DeepCopy() *EgressStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressTopologySpread) DeepCopyInto(out *EgressTopologySpread) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressTopologySpread.
+func (in *EgressTopologySpread) DeepCopy() *EgressTopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressTopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
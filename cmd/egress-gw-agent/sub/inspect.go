@@ -0,0 +1,82 @@
+package sub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/netinspect"
+)
+
+var inspectConfig struct {
+	podIP   string
+	url     string
+	timeout time.Duration
+}
+
+// inspectCmd and probeCmd are run by kubectl-egress through `kubectl exec`.
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "dump links, routes and rules in the network namespace of a pod",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cmd.SilenceUsage = true
+		nsPath, err := findPodNetNS()
+		if err != nil {
+			return err
+		}
+		st, err := netinspect.Dump(nsPath)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	},
+}
+
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "send an HTTP request from the network namespace of a pod",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cmd.SilenceUsage = true
+		if inspectConfig.url == "" {
+			return errors.New("--url is required")
+		}
+		nsPath, err := findPodNetNS()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), inspectConfig.timeout)
+		defer cancel()
+		body, err := netinspect.Get(ctx, nsPath, inspectConfig.url)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(body)
+		return err
+	},
+}
+
+func findPodNetNS() (string, error) {
+	ip := net.ParseIP(inspectConfig.podIP)
+	if ip == nil {
+		return "", errors.New("invalid --pod-ip: " + inspectConfig.podIP)
+	}
+	return netinspect.FindNetNS("/proc", ip)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{inspectCmd, probeCmd} {
+		c.Flags().StringVar(&inspectConfig.podIP, "pod-ip", "", "IP address of the pod")
+		rootCmd.AddCommand(c)
+	}
+	probeCmd.Flags().StringVar(&inspectConfig.url, "url", "", "URL to send the request")
+	probeCmd.Flags().DurationVar(&inspectConfig.timeout, "timeout", 10*time.Second, "timeout of the request")
+}
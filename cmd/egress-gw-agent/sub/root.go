@@ -17,8 +17,14 @@ var config struct {
 	healthAddr  string
 	protocolId  int
 	socketPath  string
+	stateDir    string
+	nodeName    string
 	egressPort  int
-	zapOpts     zap.Options
+	bpfDatapath bool
+	bpfPinDir   string
+
+	introspectionAddr string
+	zapOpts           zap.Options
 }
 
 var rootCmd = &cobra.Command{
@@ -50,7 +56,12 @@ func init() {
 	pf.StringVar(&config.healthAddr, "health-addr", ":9385", "bind address of health/readiness probes")
 	pf.IntVar(&config.protocolId, "protocol-id", 30, "route author ID")
 	pf.StringVar(&config.socketPath, "socket", constants.DefaultSocketPath, "UNIX domain socket path")
+	pf.StringVar(&config.nodeName, "node-name", os.Getenv(constants.EnvNodeName), "name of the node where the agent runs; Pods on the node are cached")
+	pf.StringVar(&config.stateDir, "state-dir", constants.DefaultStateDir, "directory to record pods configured by the agent")
 	pf.IntVar(&config.egressPort, "egress-port", 5555, "UDP port number for egress NAT")
+	pf.BoolVar(&config.bpfDatapath, "bpf-datapath", false, "use eBPF instead of policy routing to steer IPv4 egress traffic of client pods")
+	pf.StringVar(&config.bpfPinDir, "bpf-pin-dir", constants.DefaultBPFPinDir, "directory in bpffs to pin maps of the eBPF datapath")
+	pf.StringVar(&config.introspectionAddr, "introspection-addr", "", "loopback IP address and port of the introspection gRPC service in addition to the UNIX domain socket (disabled if empty)")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
@@ -1,17 +1,26 @@
 package sub
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/runners"
 	"net"
 	"os"
 	"time"
 
 	"github.com/go-logr/zapr"
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podnat"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podstate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -27,7 +36,7 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
-	utilruntime.Must(egressv1beta1.AddToScheme(scheme))
+	utilruntime.Must(egressv1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -40,9 +49,26 @@ func subMain() error {
 	grpcLogger := zapLogger.Named("grpc")
 	ctrl.SetLogger(zapr.NewLogger(zapLogger))
 
+	if config.nodeName == "" {
+		return errors.New("node name is not given; specify --node-name or " + constants.EnvNodeName)
+	}
+	if config.introspectionAddr != "" {
+		if err := checkLoopback(config.introspectionAddr); err != nil {
+			return fmt.Errorf("invalid --introspection-addr: %w", err)
+		}
+	}
+
 	timeout := gracefulTimeout
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                  scheme,
+		Scheme: scheme,
+		Cache: cache.Options{
+			// ADD is called only for Pods on this node.
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {
+					Field: fields.OneTermEqualSelector("spec.nodeName", config.nodeName),
+				},
+			},
+		},
 		LeaderElection:          false,
 		MetricsBindAddress:      config.metricsAddr,
 		GracefulShutdownTimeout: &timeout,
@@ -59,12 +85,29 @@ func subMain() error {
 		return err
 	}
 
+	store, err := podstate.NewStore(config.stateDir)
+	if err != nil {
+		return err
+	}
+
 	os.Remove(config.socketPath)
 	l, err := net.Listen("unix", config.socketPath)
 	if err != nil {
 		return err
 	}
-	server := runners.NewEgressGwAgent(l, mgr, config.egressPort, grpcLogger)
+	var il net.Listener
+	if config.introspectionAddr != "" {
+		il, err = net.Listen("tcp", config.introspectionAddr)
+		if err != nil {
+			return err
+		}
+	}
+	natOptions := podnat.Options{
+		EgressPort:  config.egressPort,
+		BPFDatapath: config.bpfDatapath,
+		BPFPinDir:   config.bpfPinDir,
+	}
+	server := runners.NewEgressGwAgent(l, il, mgr, store, natOptions, grpcLogger)
 	if err := mgr.Add(server); err != nil {
 		return err
 	}
@@ -77,3 +120,18 @@ func subMain() error {
 
 	return nil
 }
+
+// checkLoopback returns an error if `addr` is not a loopback IP address.
+// The introspection service is not authenticated and the agent runs in
+// the host network, so it must not be reachable from other hosts or pods.
+// Host names are rejected because they may resolve to other addresses.
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%s is not a loopback IP address", addr)
+}
@@ -0,0 +1,30 @@
+package sub
+
+import "testing"
+
+func TestCheckLoopback(t *testing.T) {
+	testCases := []struct {
+		addr string
+		ok   bool
+	}{
+		{"127.0.0.1:9386", true},
+		{"127.1.2.3:9386", true},
+		{"[::1]:9386", true},
+		{"localhost:9386", false},
+		{"0.0.0.0:9386", false},
+		{":9386", false},
+		{"192.0.2.1:9386", false},
+		{"[fd00::1]:9386", false},
+		{"127.0.0.1", false},
+	}
+
+	for _, tc := range testCases {
+		err := checkLoopback(tc.addr)
+		if tc.ok && err != nil {
+			t.Errorf("%s should be accepted: %v", tc.addr, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s should be rejected", tc.addr)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/containernetworking/cni/pkg/skel"
@@ -11,9 +14,14 @@ import (
 	"github.com/containernetworking/cni/pkg/version"
 	egressgw "github.com/ysksuzuki/egress-gw-cni-plugin"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-const rpcTimeout = 1 * time.Minute
+const (
+	rpcTimeout    = 1 * time.Minute
+	statusTimeout = 10 * time.Second
+)
 
 func cmdAdd(args *skel.CmdArgs) error {
 	conf, err := parseConfig(args.StdinData)
@@ -22,7 +30,7 @@ func cmdAdd(args *skel.CmdArgs) error {
 	}
 
 	if conf.PrevResult == nil {
-		return types.NewError(types.ErrInvalidNetworkConfig, "egress-gw must be called next to the cilium-cni", "")
+		return types.NewError(types.ErrInvalidNetworkConfig, "egress-gw must be chained after a primary CNI plugin", "")
 	}
 
 	cniArgs, err := makeCNIArgs(args)
@@ -30,27 +38,32 @@ func cmdAdd(args *skel.CmdArgs) error {
 		return err
 	}
 
-	conn, err := connect(conf.Socket)
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-
-	client := cnirpc.NewCNIClient(conn)
 	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
 	defer cancel()
 
-	resp, err := client.Add(ctx, cniArgs)
+	var data []byte
+	if conf.Kubeconfig != "" {
+		data, err = standaloneAdd(ctx, conf, cniArgs)
+	} else {
+		err = callAgent(ctx, conf.Socket, func(ctx context.Context, client cnirpc.CNIClient) error {
+			resp, err := client.Add(ctx, cniArgs)
+			if err != nil {
+				return err
+			}
+			data = resp.Result
+			return nil
+		})
+	}
 	if err != nil {
-		return convertError(err)
+		return err
 	}
 
-	result, err := current.NewResult(resp.Result)
+	result, err := current.NewResult(data)
 	if err != nil {
 		return types.NewError(types.ErrDecodingFailure, "failed to unmarshal result", err.Error())
 	}
 
-	return types.PrintResult(result, conf.CNIVersion)
+	return cnirpc.PrintResult(result, conf.CNIVersion)
 }
 
 func cmdDel(args *skel.CmdArgs) error {
@@ -64,21 +77,18 @@ func cmdDel(args *skel.CmdArgs) error {
 		return err
 	}
 
-	conn, err := connect(conf.Socket)
-	if err != nil {
-		return err
+	if conf.Kubeconfig != "" {
+		// nothing to do in standalone mode.
+		return nil
 	}
-	defer conn.Close()
 
-	client := cnirpc.NewCNIClient(conn)
 	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
 	defer cancel()
 
-	if _, err = client.Del(ctx, cniArgs); err != nil {
-		return convertError(err)
-	}
-
-	return nil
+	return callAgent(ctx, conf.Socket, func(ctx context.Context, client cnirpc.CNIClient) error {
+		_, err := client.Del(ctx, cniArgs)
+		return err
+	})
 }
 
 func cmdCheck(args *skel.CmdArgs) error {
@@ -92,24 +102,119 @@ func cmdCheck(args *skel.CmdArgs) error {
 		return err
 	}
 
+	if conf.Kubeconfig != "" {
+		// nothing to do in standalone mode.
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	return callAgent(ctx, conf.Socket, func(ctx context.Context, client cnirpc.CNIClient) error {
+		_, err := client.Check(ctx, cniArgs)
+		return err
+	})
+}
+
+// cmdStatus reports whether egress-gw-agent is ready to handle ADD.
+// In standalone mode, it reports whether the kubeconfig is usable.
+func cmdStatus(stdin []byte) error {
+	conf, err := parseConfig(stdin)
+	if err != nil {
+		return err
+	}
+
+	if conf.Kubeconfig != "" {
+		if _, err := newStandaloneClient(conf.Kubeconfig); err != nil {
+			return types.NewError(uint(cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE), "kubeconfig is not usable", err.Error())
+		}
+		return nil
+	}
+
+	// STATUS should respond promptly, so it does not retry.
 	conn, err := connect(conf.Socket)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+
 	client := cnirpc.NewCNIClient(conn)
+	_, err = client.Status(ctx, &cnirpc.CNIArgs{Path: os.Getenv("CNI_PATH"), StdinData: stdin})
+	if status.Code(err) == codes.Unavailable {
+		return types.NewError(uint(cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE), "egress-gw-agent is not available", err.Error())
+	}
+	if err != nil {
+		return convertError(err)
+	}
+	return nil
+}
+
+// cmdGC asks egress-gw-agent to clean up resources for attachments
+// that are not listed in cni.dev/valid-attachments.
+func cmdGC(stdin []byte) error {
+	conf, err := parseConfig(stdin)
+	if err != nil {
+		return err
+	}
+
+	if conf.Kubeconfig != "" {
+		// standalone mode keeps no state to clean up.
+		return nil
+	}
+
+	gcArgs := &cnirpc.GCArgs{
+		Args: &cnirpc.CNIArgs{Path: os.Getenv("CNI_PATH"), StdinData: stdin},
+	}
+	for _, a := range conf.ValidAttachments {
+		gcArgs.ValidAttachments = append(gcArgs.ValidAttachments, &cnirpc.Attachment{
+			ContainerId: a.ContainerID,
+			Ifname:      a.IfName,
+		})
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
 	defer cancel()
 
-	if _, err = client.Check(ctx, cniArgs); err != nil {
-		return convertError(err)
+	return callAgent(ctx, conf.Socket, func(ctx context.Context, client cnirpc.CNIClient) error {
+		_, err := client.GC(ctx, gcArgs)
+		return err
+	})
+}
+
+// runCommand runs STATUS or GC command added in CNI spec 1.1.0.
+// skel of the CNI library in use does not support them.
+func runCommand(cmd string) int {
+	stdin, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		err = types.NewError(types.ErrIOFailure, "failed to read stdin", err.Error())
+	} else if cmd == "STATUS" {
+		err = cmdStatus(stdin)
+	} else {
+		err = cmdGC(stdin)
+	}
+	if err == nil {
+		return 0
 	}
 
-	return nil
+	var cniErr *types.Error
+	if !errors.As(err, &cniErr) {
+		cniErr = types.NewError(types.ErrInternal, err.Error(), "")
+	}
+	if err := cniErr.Print(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return 1
 }
 
 func main() {
-	skel.PluginMain(cmdAdd, cmdCheck, cmdDel, version.PluginSupports("0.3.1", "0.4.0", "1.0.0"),
+	switch cmd := os.Getenv("CNI_COMMAND"); cmd {
+	case "STATUS", "GC":
+		os.Exit(runCommand(cmd))
+	}
+
+	skel.PluginMain(cmdAdd, cmdCheck, cmdDel, version.PluginSupports("0.3.0", "0.3.1", "0.4.0", "1.0.0", "1.1.0"),
 		fmt.Sprintf("egress-gw-cni-plugin %s", egressgw.Version()))
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc/cnirpctest"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sockName := filepath.Join(t.TempDir(), "egress-gw.sock")
+	conf := []byte(`{"cniVersion": "1.1.0", "name": "k8s", "type": "egress-gw", "socket": "` + sockName + `"}`)
+
+	err := cmdStatus(conf)
+	if code := cniErrorCode(t, err); code != uint(cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE) {
+		t.Error("STATUS should fail with PLUGIN_NOT_AVAILABLE if the agent is not running", code)
+	}
+
+	m := &cnirpctest.Server{}
+	if err := m.Start(ctx, sockName); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmdStatus(conf); err != nil {
+		t.Error("STATUS should succeed", err)
+	}
+
+	m.StatusErr = newCNIError(codes.Unavailable, cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE, "cache is not synced")
+	err = cmdStatus(conf)
+	if code := cniErrorCode(t, err); code != uint(cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE) {
+		t.Error("STATUS should fail with PLUGIN_NOT_AVAILABLE if the agent is not ready", code)
+	}
+}
+
+func TestStatusStandalone(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	conf := []byte(`{"cniVersion": "1.1.0", "name": "k8s", "type": "egress-gw", "kubeconfig": "` + kubeconfig + `"}`)
+
+	err := cmdStatus(conf)
+	if code := cniErrorCode(t, err); code != uint(cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE) {
+		t.Error("STATUS should fail with PLUGIN_NOT_AVAILABLE if kubeconfig is missing", code)
+	}
+
+	if err := cmdGC(conf); err != nil {
+		t.Error("GC should do nothing in standalone mode", err)
+	}
+}
+
+func TestGC(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sockName := filepath.Join(t.TempDir(), "egress-gw.sock")
+	m := &cnirpctest.Server{}
+	if err := m.Start(ctx, sockName); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := []byte(`{
+	"cniVersion": "1.1.0",
+	"name": "k8s",
+	"type": "egress-gw",
+	"socket": "` + sockName + `",
+	"cni.dev/valid-attachments": [
+		{"containerID": "c1", "ifname": "eth0"},
+		{"containerID": "c2", "ifname": "eth0"}
+	]
+}`)
+	if err := cmdGC(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	gcArgs := m.GCArgs()
+	if gcArgs == nil {
+		t.Fatal("GC was not called")
+	}
+	if string(gcArgs.Args.StdinData) != string(conf) {
+		t.Error("the network configuration should be passed")
+	}
+	va := gcArgs.ValidAttachments
+	if len(va) != 2 || va[0].ContainerId != "c1" || va[1].ContainerId != "c2" || va[1].Ifname != "eth0" {
+		t.Error("unexpected valid attachments", va)
+	}
+}
+
+func TestParseConfigVersion110(t *testing.T) {
+	conf := []byte(`{
+	"cniVersion": "1.1.0",
+	"name": "k8s",
+	"type": "egress-gw",
+	"prevResult": {
+		"cniVersion": "1.1.0",
+		"ips": [{"address": "10.1.0.5/32"}]
+	}
+}`)
+	pc, err := parseConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.CNIVersion != "1.1.0" {
+		t.Error(`pc.CNIVersion != "1.1.0"`, pc.CNIVersion)
+	}
+	if pc.PrevResult == nil {
+		t.Error("pc.PrevResult should not be nil")
+	}
+}
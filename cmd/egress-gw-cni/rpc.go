@@ -3,15 +3,24 @@ package main
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/containernetworking/cni/pkg/skel"
 	"github.com/containernetworking/cni/pkg/types"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
 
+// Intervals of retries while egress-gw-agent is unavailable.
+const (
+	retryInitialInterval = 100 * time.Millisecond
+	retryMaxInterval     = 2 * time.Second
+)
+
 // makeCNIArgs creates *CNIArgs.
 func makeCNIArgs(args *skel.CmdArgs) (*cnirpc.CNIArgs, error) {
 	env := &PluginEnvArgs{}
@@ -36,25 +45,68 @@ func connect(sock string) (*grpc.ClientConn, error) {
 	dialFunc := func(ctx context.Context, a string) (net.Conn, error) {
 		return dialer.DialContext(ctx, "unix", a)
 	}
-	conn, err := grpc.Dial(sock, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialFunc))
+	// reconnect as quickly as retries in callAgent.
+	bc := backoff.DefaultConfig
+	bc.BaseDelay = retryInitialInterval
+	bc.MaxDelay = retryMaxInterval
+	conn, err := grpc.Dial(sock, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialFunc),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: bc, MinConnectTimeout: retryMaxInterval}))
 	if err != nil {
 		return nil, types.NewError(types.ErrTryAgainLater, "failed to connect to "+sock, err.Error())
 	}
 	return conn, nil
 }
 
+// callAgent connects to egress-gw-agent and calls f.
+//
+// egress-gw-agent may be unavailable for a while, e.g. when it is restarting.
+// While f fails with codes.Unavailable, it is retried with exponential backoff
+// until ctx is done.  If the agent does not respond before ctx is done, the
+// error is also converted to ErrTryAgainLater.
+func callAgent(ctx context.Context, sock string, f func(context.Context, cnirpc.CNIClient) error) error {
+	conn, err := connect(sock)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := cnirpc.NewCNIClient(conn)
+	interval := retryInitialInterval
+	for {
+		err := f(ctx, client)
+		if err == nil {
+			return nil
+		}
+		if status.Code(err) != codes.Unavailable {
+			return convertError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return convertError(err)
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > retryMaxInterval {
+			interval = retryMaxInterval
+		}
+	}
+}
+
 // convertError turns err returned from gRPC library into CNI's types.Error
 func convertError(err error) error {
 	st := status.Convert(err)
 	details := st.Details()
-	if len(details) != 1 {
-		return types.NewError(types.ErrInternal, st.Message(), err.Error())
+	if len(details) == 1 {
+		if cniErr, ok := details[0].(*cnirpc.CNIError); ok {
+			return types.NewError(uint(cniErr.Code), cniErr.Msg, cniErr.Details)
+		}
 	}
 
-	cniErr, ok := details[0].(*cnirpc.CNIError)
-	if !ok {
-		types.NewError(types.ErrInternal, st.Message(), err.Error())
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		// the agent is not running, restarting, or did not respond in time.
+		return types.NewError(types.ErrTryAgainLater, "egress-gw-agent is unavailable", st.Message())
 	}
-
-	return types.NewError(uint(cniErr.Code), cniErr.Msg, cniErr.Details)
+	return types.NewError(types.ErrInternal, st.Message(), err.Error())
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc/cnirpctest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func newCNIError(c codes.Code, cniCode cnirpc.ErrorCode, msg string) error {
+	st, err := status.New(c, msg).WithDetails(&cnirpc.CNIError{Code: cniCode, Msg: msg, Details: "detail"})
+	if err != nil {
+		panic(err)
+	}
+	return st.Err()
+}
+
+func cniErrorCode(t *testing.T, err error) uint {
+	t.Helper()
+
+	var cniErr *types.Error
+	if !errors.As(err, &cniErr) {
+		t.Fatalf("not a CNI error: %v", err)
+	}
+	return cniErr.Code
+}
+
+func TestConvertError(t *testing.T) {
+	err := convertError(newCNIError(codes.Internal, cnirpc.ErrorCode_TRY_AGAIN_LATER, "abc"))
+	if code := cniErrorCode(t, err); code != types.ErrTryAgainLater {
+		t.Error("TRY_AGAIN_LATER should be converted to ErrTryAgainLater", code)
+	}
+
+	err = convertError(newCNIError(codes.NotFound, cnirpc.ErrorCode_UNKNOWN_CONTAINER, "abc"))
+	if code := cniErrorCode(t, err); code != types.ErrUnknownContainer {
+		t.Error("UNKNOWN_CONTAINER should be converted to ErrUnknownContainer", code)
+	}
+
+	err = convertError(status.Error(codes.Unavailable, "connection refused"))
+	if code := cniErrorCode(t, err); code != types.ErrTryAgainLater {
+		t.Error("Unavailable should be converted to ErrTryAgainLater", code)
+	}
+
+	err = convertError(status.Error(codes.DeadlineExceeded, "context deadline exceeded"))
+	if code := cniErrorCode(t, err); code != types.ErrTryAgainLater {
+		t.Error("DeadlineExceeded should be converted to ErrTryAgainLater", code)
+	}
+
+	st, err := status.New(codes.Internal, "abc").WithDetails(&emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = convertError(st.Err())
+	if code := cniErrorCode(t, err); code != types.ErrInternal {
+		t.Error("unknown details should be converted to ErrInternal", code)
+	}
+}
+
+func TestCallAgent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sockName := filepath.Join(t.TempDir(), "egress-gw.sock")
+
+	// the agent is not running.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer shortCancel()
+	err := callAgent(shortCtx, sockName, func(ctx context.Context, client cnirpc.CNIClient) error {
+		_, err := client.Add(ctx, &cnirpc.CNIArgs{})
+		return err
+	})
+	if code := cniErrorCode(t, err); code != types.ErrTryAgainLater {
+		t.Error("unavailable agent should result in ErrTryAgainLater", code)
+	}
+
+	// the agent starts while retrying.
+	m := &cnirpctest.Server{
+		AddResult: []byte("{}"),
+		CheckErr:  newCNIError(codes.Internal, cnirpc.ErrorCode_INTERNAL, "abc"),
+	}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		if err := m.Start(ctx, sockName); err != nil {
+			panic(err)
+		}
+	}()
+
+	var calls int
+	var resp *cnirpc.AddResponse
+	err = callAgent(ctx, sockName, func(ctx context.Context, client cnirpc.CNIClient) error {
+		calls++
+		var err error
+		resp, err = client.Add(ctx, &cnirpc.CNIArgs{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Result) != "{}" {
+		t.Error("unexpected result", string(resp.Result))
+	}
+	if calls < 2 {
+		t.Error("Add should have been retried", calls)
+	}
+
+	// errors other than Unavailable are not retried.
+	calls = 0
+	err = callAgent(ctx, sockName, func(ctx context.Context, client cnirpc.CNIClient) error {
+		calls++
+		_, err := client.Check(ctx, &cnirpc.CNIArgs{})
+		return err
+	})
+	if code := cniErrorCode(t, err); code != types.ErrInternal {
+		t.Error("unexpected error code", code)
+	}
+	if calls != 1 {
+		t.Error("Check should not be retried", calls)
+	}
+}
+
+func TestCallAgentTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sockName := filepath.Join(t.TempDir(), "egress-gw.sock")
+	m := &cnirpctest.Server{Delay: 10 * time.Second}
+	if err := m.Start(ctx, sockName); err != nil {
+		t.Fatal(err)
+	}
+
+	// the agent does not respond before the deadline.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer shortCancel()
+	err := callAgent(shortCtx, sockName, func(ctx context.Context, client cnirpc.CNIClient) error {
+		_, err := client.Add(ctx, &cnirpc.CNIArgs{})
+		return err
+	})
+	if code := cniErrorCode(t, err); code != types.ErrTryAgainLater {
+		t.Error("timeout should result in ErrTryAgainLater", code)
+	}
+}
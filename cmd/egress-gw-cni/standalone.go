@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+
+	"github.com/containernetworking/cni/pkg/types"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podnat"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newStandaloneClient returns a client to read objects in standalone mode.
+func newStandaloneClient(kubeconfig string) (client.Client, error) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, types.NewError(types.ErrInvalidNetworkConfig, "failed to load kubeconfig "+kubeconfig, err.Error())
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := egressv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, types.NewError(types.ErrTryAgainLater, "failed to create a client", err.Error())
+	}
+	return c, nil
+}
+
+// standaloneAdd sets up egress NAT for the pod in the same way as
+// egress-gw-agent, and returns the result of ADD serialized into JSON.
+func standaloneAdd(ctx context.Context, conf *PluginConf, cniArgs *cnirpc.CNIArgs) ([]byte, error) {
+	c, err := newStandaloneClient(conf.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// CNI plugins must not write logs to stdout.
+	logger, err := zap.NewProduction()
+	if err != nil {
+		return nil, err
+	}
+	defer logger.Sync()
+
+	opts := podnat.Options{
+		EgressPort:  conf.EgressPort,
+		BPFDatapath: conf.BPFDatapath,
+	}
+	result, _, err := podnat.Add(ctx, c, cniArgs, opts, logger)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return result, nil
+}
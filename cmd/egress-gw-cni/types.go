@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/containernetworking/cni/pkg/types"
-	"github.com/containernetworking/cni/pkg/version"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 )
 
@@ -26,24 +26,45 @@ func (e PluginEnvArgs) Map() map[string]string {
 	}
 }
 
+const defaultEgressPort = 5555
+
 // PluginConf represents JSON netconf for egress-gw.
 type PluginConf struct {
 	types.NetConf
 
 	// egress-gw specific flags
 	Socket string `json:"socket"`
+
+	// Kubeconfig is the path to a kubeconfig file.  If given, the plugin
+	// runs in standalone mode and sets up egress NAT without egress-gw-agent.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// EgressPort and BPFDatapath are used in standalone mode.
+	// They correspond to flags of egress-gw-agent.
+	EgressPort  int  `json:"egressPort,omitempty"`
+	BPFDatapath bool `json:"bpfDatapath,omitempty"`
+
+	// ValidAttachments is given for GC command.
+	ValidAttachments []Attachment `json:"cni.dev/valid-attachments,omitempty"`
+}
+
+// Attachment is an element of cni.dev/valid-attachments.
+type Attachment struct {
+	ContainerID string `json:"containerID"`
+	IfName      string `json:"ifname"`
 }
 
 func parseConfig(stdin []byte) (*PluginConf, error) {
 	conf := &PluginConf{
-		Socket: constants.DefaultSocketPath,
+		Socket:     constants.DefaultSocketPath,
+		EgressPort: defaultEgressPort,
 	}
 
 	if err := json.Unmarshal(stdin, conf); err != nil {
 		return nil, fmt.Errorf("failed to parse network configuration: %w", err)
 	}
 
-	if err := version.ParsePrevResult(&conf.NetConf); err != nil {
+	if err := cnirpc.ParsePrevResult(&conf.NetConf); err != nil {
 		return nil, fmt.Errorf("failed to parse prev result: %w", err)
 	}
 
@@ -115,3 +115,49 @@ func TestParseConfig(t *testing.T) {
 		t.Error("pc.Result should not be nil")
 	}
 }
+
+func TestParseConfigStandalone(t *testing.T) {
+	conf := []byte(`
+{
+	"cniVersion": "1.0.0",
+	"name": "k8s",
+	"type": "egress-gw",
+	"kubeconfig": "/etc/cni/net.d/egress-gw.kubeconfig"
+}
+`)
+
+	pc, err := parseConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Kubeconfig != "/etc/cni/net.d/egress-gw.kubeconfig" {
+		t.Error(`pc.Kubeconfig != "/etc/cni/net.d/egress-gw.kubeconfig"`)
+	}
+	if pc.EgressPort != defaultEgressPort {
+		t.Error(`pc.EgressPort != defaultEgressPort`)
+	}
+	if pc.BPFDatapath {
+		t.Error(`pc.BPFDatapath should be false`)
+	}
+
+	conf = []byte(`
+{
+	"cniVersion": "1.0.0",
+	"name": "k8s",
+	"type": "egress-gw",
+	"kubeconfig": "/etc/cni/net.d/egress-gw.kubeconfig",
+	"egressPort": 6666,
+	"bpfDatapath": true
+}
+`)
+	pc, err = parseConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.EgressPort != 6666 {
+		t.Error(`pc.EgressPort != 6666`)
+	}
+	if !pc.BPFDatapath {
+		t.Error(`pc.BPFDatapath should be true`)
+	}
+}
@@ -13,13 +13,15 @@ import (
 )
 
 var config struct {
-	metricsAddr string
-	healthAddr  string
-	webhookAddr string
-	certDir     string
-	gcInterval  time.Duration
-	egressPort  int32
-	zapOpts     zap.Options
+	metricsAddr   string
+	healthAddr    string
+	webhookAddr   string
+	certDir       string
+	gcInterval    time.Duration
+	egressPort    int32
+	clusterCIDRs  []string
+	networkPolicy string
+	zapOpts       zap.Options
 }
 
 var rootCmd = &cobra.Command{
@@ -50,6 +52,8 @@ func init() {
 	pf.StringVar(&config.certDir, "cert-dir", "/certs", "directory to locate TLS certs for webhook")
 	pf.DurationVar(&config.gcInterval, "gc-interval", 1*time.Hour, "garbage collection interval")
 	pf.Int32Var(&config.egressPort, "egress-port", 5555, "UDP port number used by egress")
+	pf.StringSliceVar(&config.clusterCIDRs, "cluster-cidrs", nil, "pod and service CIDRs of the cluster to warn Egress destinations overlapping with them")
+	pf.StringVar(&config.networkPolicy, "network-policy", "none", "network policy generated for egress pods: auto, kubernetes, cilium, or none")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
@@ -7,9 +7,11 @@ import (
 	"strconv"
 	"time"
 
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/controllers"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/webhooks"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -22,6 +24,7 @@ import (
 
 const (
 	gracefulTimeout = 20 * time.Second
+	metricsTimeout  = 5 * time.Second
 )
 
 var (
@@ -31,6 +34,7 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(egressv1.AddToScheme(scheme))
 	utilruntime.Must(egressv1beta1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
@@ -48,6 +52,15 @@ func subMain() error {
 		return fmt.Errorf("invalid webhook address: %w", err)
 	}
 
+	var clusterCIDRs []*net.IPNet
+	for _, c := range config.clusterCIDRs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return fmt.Errorf("invalid cluster CIDR: %w", err)
+		}
+		clusterCIDRs = append(clusterCIDRs, n)
+	}
+
 	timeout := gracefulTimeout
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                  scheme,
@@ -82,10 +95,12 @@ func subMain() error {
 		return err
 	}
 	egressctrl := controllers.EgressReconciler{
-		Client: mgr.GetClient(),
-		Scheme: scheme,
-		Image:  img,
-		Port:   config.egressPort,
+		Client:        mgr.GetClient(),
+		Scheme:        scheme,
+		Image:         img,
+		Port:          config.egressPort,
+		Metrics:       controllers.NewHTTPMetricsFetcher(metricsTimeout),
+		NetworkPolicy: controllers.NetworkPolicyMode(config.networkPolicy),
 	}
 	if err := egressctrl.SetupWithManager(mgr); err != nil {
 		return err
@@ -95,7 +110,13 @@ func subMain() error {
 		return err
 	}
 
-	if err := (&egressv1beta1.Egress{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&egressv1.Egress{}).SetupWebhookWithManager(mgr, clusterCIDRs); err != nil {
+		return err
+	}
+	if err := (&egressv1.ClusterEgress{}).SetupWebhookWithManager(mgr, clusterCIDRs); err != nil {
+		return err
+	}
+	if err := webhooks.SetupPodWebhookWithManager(mgr); err != nil {
 		return err
 	}
 
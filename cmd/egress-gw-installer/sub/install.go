@@ -1,45 +1,317 @@
 package sub
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 )
 
-func installCniConf(cniConfName, cniEtcDir, cniNetConf, cniNetConfFile string) error {
+// pluginType is the type of the plugin entry of egress-gw-cni.
+const pluginType = "egress-gw-cni"
+
+// chainedConfSuffix replaces the extension of a single plugin configuration
+// of the primary plugin to name the network configuration list chaining
+// egress-gw-cni after it.  Because '-' sorts before '.', the list sorts
+// before the configuration of the primary plugin, and kubelet chooses it.
+const chainedConfSuffix = "-egress-gw.conflist"
+
+var errNoPrimaryConf = errors.New("no CNI configuration file of the primary plugin")
+
+// netConf is the network configuration to be installed.
+type netConf struct {
+	// plugin is the plugin entry of egress-gw-cni.
+	plugin map[string]interface{}
+
+	// confList is the network configuration list written when there is
+	// no configuration of the primary plugin.  It may be nil.
+	confList map[string]interface{}
+}
+
+// loadNetConf loads the network configuration from `cniNetConf`, or from
+// `cniNetConfFile` if `cniNetConf` is empty.
+//
+// The configuration is either a plugin entry of egress-gw-cni, or a network
+// configuration list having the entry.
+func loadNetConf(cniNetConf, cniNetConfFile string) (*netConf, error) {
 	data := []byte(cniNetConf)
 	if cniNetConf == "" {
 		bData, err := os.ReadFile(cniNetConfFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		data = bData
 	}
 
-	err := os.MkdirAll(cniEtcDir, 0755)
+	conf := make(map[string]interface{})
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse the network configuration: %w", err)
+	}
+
+	if _, ok := conf["plugins"]; !ok {
+		if conf["type"] != pluginType {
+			return nil, fmt.Errorf("the type of the plugin entry is not %s", pluginType)
+		}
+		return &netConf{plugin: conf}, nil
+	}
+
+	plugins, ok := conf["plugins"].([]interface{})
+	if !ok {
+		return nil, errors.New("plugins is not a list")
+	}
+	for _, p := range plugins {
+		if p, ok := p.(map[string]interface{}); ok && p["type"] == pluginType {
+			return &netConf{plugin: p, confList: conf}, nil
+		}
+	}
+	return nil, fmt.Errorf("the network configuration list has no plugin entry of %s", pluginType)
+}
+
+// installCniConf chains egress-gw-cni after the primary CNI plugin by
+// adding the plugin entry to the configuration list of the primary plugin.
+// If the list already has the entry, the entry is updated.
+// The list is rewritten only when it is changed.
+//
+// A network configuration file of a single plugin is left as it is because
+// the primary plugin may rewrite it at any time.  Instead, a list chaining
+// egress-gw-cni after the plugin is written next to it with the name
+// ending with `chainedConfSuffix`.  If there is no configuration of the
+// primary plugin, `nc.confList` is written as `cniConfName` instead.
+// Other files in `cniEtcDir` are kept as they are.
+func installCniConf(cniConfName, cniEtcDir string, nc *netConf) error {
+	if err := os.MkdirAll(cniEtcDir, 0755); err != nil {
+		return err
+	}
+
+	primary, err := findPrimaryConf(cniEtcDir)
+	if errors.Is(err, errNoPrimaryConf) {
+		// lists chained after a configuration that no longer exists are stale.
+		if err := removeChainedConfs(cniEtcDir, ""); err != nil {
+			return err
+		}
+		if nc.confList != nil {
+			data, err := json.MarshalIndent(nc.confList, "", "  ")
+			if err != nil {
+				return err
+			}
+			return writeFileAtomic(filepath.Join(cniEtcDir, cniConfName), data)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
-	files, err := os.ReadDir(cniEtcDir)
+	data, err := os.ReadFile(primary)
 	if err != nil {
 		return err
 	}
-	for _, fi := range files {
-		if fi.IsDir() {
+	confList, err := loadConfList(data)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", primary, err)
+	}
+
+	dest := primary
+	if filepath.Ext(primary) != ".conflist" {
+		dest = strings.TrimSuffix(primary, filepath.Ext(primary)) + chainedConfSuffix
+	}
+	if err := removeChainedConfs(cniEtcDir, dest); err != nil {
+		return err
+	}
+
+	changed := setPlugin(confList, nc.plugin)
+	data, err = json.MarshalIndent(confList, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dest == primary {
+		if !changed {
+			return nil
+		}
+	} else if current, err := os.ReadFile(dest); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return writeFileAtomic(dest, data)
+}
+
+// isChainedConf returns true if `path` is a configuration list written by
+// installCniConf for a single plugin configuration of the primary plugin.
+func isChainedConf(path string) bool {
+	return strings.HasSuffix(filepath.Base(path), chainedConfSuffix)
+}
+
+// removeChainedConfs removes configuration lists written for single plugin
+// configurations of the primary plugin, except `keep`.
+func removeChainedConfs(cniEtcDir, keep string) error {
+	files, err := confFiles(cniEtcDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if !isChainedConf(path) || path == keep {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// uninstallCniConf removes plugin entries of egress-gw-cni from network
+// configuration lists in `cniEtcDir`, and lists written for single plugin
+// configurations.  Other plugin entries and files are kept as they are.
+func uninstallCniConf(cniEtcDir string) error {
+	if err := removeChainedConfs(cniEtcDir, ""); err != nil {
+		return err
+	}
+
+	files, err := confFiles(cniEtcDir)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		confList, err := loadConfList(data)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if !removePlugin(confList) {
 			continue
 		}
-		err := os.Remove(filepath.Join(cniEtcDir, fi.Name()))
+
+		data, err = json.MarshalIndent(confList, "", "  ")
 		if err != nil {
 			return err
 		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	f, err := os.Create(filepath.Join(cniEtcDir, cniConfName))
+// setPlugin adds or updates the plugin entry of egress-gw-cni in `confList`.
+// It returns true if `confList` is changed.
+func setPlugin(confList, plugin map[string]interface{}) bool {
+	plugins := confList["plugins"].([]interface{})
+	for i, p := range plugins {
+		if p, ok := p.(map[string]interface{}); !ok || p["type"] != pluginType {
+			continue
+		}
+		if reflect.DeepEqual(p, plugin) {
+			return false
+		}
+		plugins[i] = plugin
+		return true
+	}
+
+	confList["plugins"] = append(plugins, plugin)
+	return true
+}
+
+// removePlugin removes plugin entries of egress-gw-cni from `confList`.
+// It returns true if `confList` is changed.
+func removePlugin(confList map[string]interface{}) bool {
+	plugins := confList["plugins"].([]interface{})
+	kept := make([]interface{}, 0, len(plugins))
+	for _, p := range plugins {
+		if p, ok := p.(map[string]interface{}); ok && p["type"] == pluginType {
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	if len(kept) == len(plugins) {
+		return false
+	}
+	confList["plugins"] = kept
+	return true
+}
+
+// confFiles returns the paths of network configuration files in
+// `cniEtcDir` in lexicographic order.
+func confFiles(cniEtcDir string) ([]string, error) {
+	files, err := os.ReadDir(cniEtcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, fi := range files {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		switch filepath.Ext(fi.Name()) {
+		case ".conf", ".conflist", ".json":
+			paths = append(paths, filepath.Join(cniEtcDir, fi.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// findPrimaryConf returns the path of the network configuration file
+// of the primary CNI plugin.  As kubelet does, it is the first file in
+// lexicographic order, except lists written by installCniConf.
+func findPrimaryConf(cniEtcDir string) (string, error) {
+	files, err := confFiles(cniEtcDir)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range files {
+		if !isChainedConf(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w in %s", errNoPrimaryConf, cniEtcDir)
+}
+
+// loadConfList loads a network configuration list.  If `data` is a network
+// configuration of a single plugin, it is converted into a list.
+func loadConfList(data []byte) (map[string]interface{}, error) {
+	conf := make(map[string]interface{})
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+
+	if _, ok := conf["plugins"]; !ok {
+		if _, ok := conf["type"]; !ok {
+			return nil, errors.New("neither plugins nor type is specified")
+		}
+		return map[string]interface{}{
+			"cniVersion": conf["cniVersion"],
+			"name":       conf["name"],
+			"plugins":    []interface{}{conf},
+		}, nil
+	}
+
+	if _, ok := conf["plugins"].([]interface{}); !ok {
+		return nil, errors.New("plugins is not a list")
+	}
+	return conf, nil
+}
+
+// writeFileAtomic writes `data` to `path` via a temporary file so that
+// kubelet and the primary plugin never read a partially written file.
+// The name of the temporary file starts with a dot so that it is not
+// taken as a network configuration file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
 
 	err = f.Chmod(0644)
 	if err != nil {
@@ -51,7 +323,12 @@ func installCniConf(cniConfName, cniEtcDir, cniNetConf, cniNetConfFile string) e
 		return err
 	}
 
-	return f.Sync()
+	err = f.Sync()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
 }
 
 func installEgressGW(egressGWPath, cniBinDir string) error {
@@ -90,5 +367,5 @@ func installEgressGW(egressGWPath, cniBinDir string) error {
 		return err
 	}
 
-	return os.Rename(g.Name(), filepath.Join(cniBinDir, "egress-gw-cni"))
+	return os.Rename(g.Name(), filepath.Join(cniBinDir, pluginType))
 }
@@ -0,0 +1,331 @@
+package sub
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const egressGWEntry = `{"type": "egress-gw-cni", "socket": "/run/egress-gw.sock"}`
+
+func readConfList(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := make(map[string]interface{})
+	if err := json.Unmarshal(data, &conf); err != nil {
+		t.Fatal(err)
+	}
+	return conf
+}
+
+func install(t *testing.T, cniConfName, cniEtcDir, cniNetConf string) error {
+	t.Helper()
+
+	nc, err := loadNetConf(cniNetConf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return installCniConf(cniConfName, cniEtcDir, nc)
+}
+
+func pluginTypes(t *testing.T, conf map[string]interface{}) []string {
+	t.Helper()
+
+	var types []string
+	for _, p := range conf["plugins"].([]interface{}) {
+		types = append(types, p.(map[string]interface{})["type"].(string))
+	}
+	return types
+}
+
+func TestChainCniConfList(t *testing.T) {
+	dir := t.TempDir()
+	calico := `{
+  "name": "k8s-pod-network",
+  "cniVersion": "0.3.1",
+  "plugins": [
+    {"type": "calico", "ipam": {"type": "calico-ipam"}},
+    {"type": "portmap", "snat": true, "capabilities": {"portMappings": true}}
+  ]
+}`
+	other := `{"cniVersion": "0.3.1", "name": "other", "type": "bridge"}`
+	if err := os.WriteFile(filepath.Join(dir, "10-calico.conflist"), []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "99-other.conf"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := install(t, "", dir, egressGWEntry); err != nil {
+			t.Fatal(err)
+		}
+
+		conf := readConfList(t, filepath.Join(dir, "10-calico.conflist"))
+		if conf["name"] != "k8s-pod-network" || conf["cniVersion"] != "0.3.1" {
+			t.Errorf("unexpected network configuration: %v", conf)
+		}
+		types := pluginTypes(t, conf)
+		if !reflect.DeepEqual(types, []string{"calico", "portmap", "egress-gw-cni"}) {
+			t.Errorf("unexpected plugins: %v", types)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "99-other.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != other {
+		t.Error("configuration files other than the primary one should not be changed", string(data))
+	}
+}
+
+func TestChainCniConf(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "10-mynet.conf")
+	chained := filepath.Join(dir, "10-mynet-egress-gw.conflist")
+	bridge := `{
+  "cniVersion": "1.0.0",
+  "name": "mynet",
+  "type": "bridge",
+  "bridge": "cni0",
+  "isGateway": true,
+  "ipam": {"type": "host-local", "subnet": "10.22.0.0/16"}
+}`
+	if err := os.WriteFile(primary, []byte(bridge), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "10-mynet.conflist"), []byte(`{"name": "other", "plugins": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := install(t, "", dir, egressGWEntry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(primary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != bridge {
+		t.Error("the configuration of the primary plugin should not be changed", string(data))
+	}
+	files, err := confFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[0] != chained {
+		t.Error("the chained list should be chosen by kubelet", files)
+	}
+
+	conf := readConfList(t, chained)
+	if conf["name"] != "mynet" || conf["cniVersion"] != "1.0.0" {
+		t.Errorf("unexpected network configuration: %v", conf)
+	}
+	types := pluginTypes(t, conf)
+	if !reflect.DeepEqual(types, []string{"bridge", "egress-gw-cni"}) {
+		t.Errorf("unexpected plugins: %v", types)
+	}
+	first := conf["plugins"].([]interface{})[0].(map[string]interface{})
+	if first["bridge"] != "cni0" {
+		t.Errorf("fields of the primary plugin should be kept: %v", first)
+	}
+
+	// the list follows changes of the primary plugin.
+	bridge = `{"cniVersion": "1.0.0", "name": "mynet", "type": "bridge", "bridge": "cni1"}`
+	if err := os.WriteFile(primary, []byte(bridge), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := install(t, "", dir, egressGWEntry); err != nil {
+		t.Fatal(err)
+	}
+	first = readConfList(t, chained)["plugins"].([]interface{})[0].(map[string]interface{})
+	if first["bridge"] != "cni1" {
+		t.Errorf("the list should be updated: %v", first)
+	}
+
+	// the list is replaced when the primary plugin renames its configuration.
+	if err := os.Rename(primary, filepath.Join(dir, "05-mynet.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := install(t, "", dir, egressGWEntry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(chained); !errors.Is(err, os.ErrNotExist) {
+		t.Error("the stale list should be removed", err)
+	}
+	types = pluginTypes(t, readConfList(t, filepath.Join(dir, "05-mynet-egress-gw.conflist")))
+	if !reflect.DeepEqual(types, []string{"bridge", "egress-gw-cni"}) {
+		t.Errorf("unexpected plugins: %v", types)
+	}
+
+	// the list is removed when the primary plugin removes its configuration.
+	if err := os.Remove(filepath.Join(dir, "05-mynet.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "10-mynet.conflist")); err != nil {
+		t.Fatal(err)
+	}
+	err = install(t, "", dir, egressGWEntry)
+	if !errors.Is(err, errNoPrimaryConf) {
+		t.Error("expected errNoPrimaryConf, got", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("the stale list should be removed: %v", entries)
+	}
+}
+
+func TestChainCniConfNoPrimary(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := install(t, "", dir, egressGWEntry)
+	if !errors.Is(err, errNoPrimaryConf) {
+		t.Error("expected errNoPrimaryConf, got", err)
+	}
+}
+
+func TestInstallCniConfList(t *testing.T) {
+	dir := t.TempDir()
+	netconf := `{"cniVersion": "0.3.1", "name": "cilium", "plugins": [{"type": "cilium-cni"}, ` + egressGWEntry + `]}`
+
+	// without the configuration of the primary plugin, the list is written.
+	if err := install(t, defaultCniConfName, dir, netconf); err != nil {
+		t.Fatal(err)
+	}
+	conf := readConfList(t, filepath.Join(dir, defaultCniConfName))
+	if conf["name"] != "cilium" {
+		t.Errorf("unexpected network configuration: %v", conf)
+	}
+	types := pluginTypes(t, conf)
+	if !reflect.DeepEqual(types, []string{"cilium-cni", "egress-gw-cni"}) {
+		t.Errorf("unexpected plugins: %v", types)
+	}
+
+	// with the configuration of the primary plugin, the entry is added to it.
+	os.Remove(filepath.Join(dir, defaultCniConfName))
+	calico := `{"cniVersion": "0.3.1", "name": "k8s-pod-network", "plugins": [{"type": "calico"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "10-calico.conflist"), []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := install(t, defaultCniConfName, dir, netconf); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "10-calico.conflist" {
+		t.Errorf("unexpected files: %v", entries)
+	}
+	types = pluginTypes(t, readConfList(t, filepath.Join(dir, "10-calico.conflist")))
+	if !reflect.DeepEqual(types, []string{"calico", "egress-gw-cni"}) {
+		t.Errorf("unexpected plugins: %v", types)
+	}
+}
+
+func TestInstallCniConfUpdate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "10-calico.conflist")
+	calico := `{"cniVersion": "0.3.1", "name": "k8s-pod-network", "plugins": [{"type": "calico"}, ` + egressGWEntry + `, {"type": "portmap"}]}`
+	if err := os.WriteFile(path, []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file is not rewritten if the entry is up to date.
+	if err := install(t, "", dir, egressGWEntry); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != calico {
+		t.Error("the configuration should not be rewritten", string(data))
+	}
+
+	if err := install(t, "", dir, `{"type": "egress-gw-cni", "socket": "/tmp/egress-gw.sock"}`); err != nil {
+		t.Fatal(err)
+	}
+	conf := readConfList(t, path)
+	types := pluginTypes(t, conf)
+	if !reflect.DeepEqual(types, []string{"calico", "egress-gw-cni", "portmap"}) {
+		t.Errorf("the entry should be updated in place: %v", types)
+	}
+	entry := conf["plugins"].([]interface{})[1].(map[string]interface{})
+	if entry["socket"] != "/tmp/egress-gw.sock" {
+		t.Errorf("the entry is not updated: %v", entry)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files should be removed: %v", entries)
+	}
+}
+
+func TestLoadNetConf(t *testing.T) {
+	for _, netconf := range []string{
+		`{"type": "bridge"}`,
+		`{"cniVersion": "0.3.1", "name": "cilium", "plugins": [{"type": "cilium-cni"}]}`,
+		`{"plugins": {}}`,
+		`not json`,
+	} {
+		if _, err := loadNetConf(netconf, ""); err == nil {
+			t.Errorf("%s should be rejected", netconf)
+		}
+	}
+}
+
+func TestUninstallCniConf(t *testing.T) {
+	dir := t.TempDir()
+	calico := `{"cniVersion": "0.3.1", "name": "k8s-pod-network", "plugins": [{"type": "calico"}, ` + egressGWEntry + `]}`
+	other := `{"cniVersion": "0.3.1", "name": "other", "plugins": [{"type": "bridge"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "10-calico.conflist"), []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "99-other.conflist"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "05-mynet-egress-gw.conflist"), []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := uninstallCniConf(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	types := pluginTypes(t, readConfList(t, filepath.Join(dir, "10-calico.conflist")))
+	if !reflect.DeepEqual(types, []string{"calico"}) {
+		t.Errorf("unexpected plugins: %v", types)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "99-other.conflist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != other {
+		t.Error("configuration files without egress-gw-cni should not be changed", string(data))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "05-mynet-egress-gw.conflist")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("the chained list should be removed", err)
+	}
+}
@@ -30,7 +30,12 @@ var rootCmd = &cobra.Command{
 		cniNetConf := viper.GetString("CNI_NETCONF")
 		cniNetConfFile := viper.GetString("CNI_NETCONF_FILE")
 
-		err := installCniConf(cniConfName, cniEtcDir, cniNetConf, cniNetConfFile)
+		nc, err := loadNetConf(cniNetConf, cniNetConfFile)
+		if err != nil {
+			return err
+		}
+
+		err = installCniConf(cniConfName, cniEtcDir, nc)
 		if err != nil {
 			return err
 		}
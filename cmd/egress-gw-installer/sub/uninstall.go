@@ -0,0 +1,22 @@
+package sub
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "remove egress-gw-cni from CNI configuration files",
+	Long: `Remove plugin entries of egress-gw-cni from network configuration
+lists in the CNI configuration directory, and lists written for single
+plugin configurations.  Other plugin entries and files are kept as they are.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return uninstallCniConf(viper.GetString("CNI_ETC_DIR"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+}
@@ -0,0 +1,89 @@
+package sub
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// resyncInterval is the interval to check the network configuration
+// even without events from the file system.
+const resyncInterval = 1 * time.Minute
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "keep egress-gw-cni chained after the primary CNI plugin",
+	Long: `Watch the CNI configuration directory and add the plugin entry of
+egress-gw-cni again when the primary CNI plugin rewrites its configuration.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		nc, err := loadNetConf(viper.GetString("CNI_NETCONF"), viper.GetString("CNI_NETCONF_FILE"))
+		if err != nil {
+			return err
+		}
+
+		return watchCniConf(cmd.Context(), viper.GetString("CNI_CONF_NAME"), viper.GetString("CNI_ETC_DIR"), nc)
+	},
+}
+
+// watchCniConf calls installCniConf whenever a file in `cniEtcDir` is
+// changed until `ctx` is done.
+func watchCniConf(ctx context.Context, cniConfName, cniEtcDir string, nc *netConf) error {
+	if err := os.MkdirAll(cniEtcDir, 0755); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(cniEtcDir); err != nil {
+		return err
+	}
+
+	install := func() {
+		if err := installCniConf(cniConfName, cniEtcDir, nc); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to install the network configuration: %v\n", err)
+		}
+	}
+	install()
+
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if strings.HasPrefix(filepath.Base(ev.Name), ".") {
+				// temporary files
+				continue
+			}
+			install()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+			install()
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+}
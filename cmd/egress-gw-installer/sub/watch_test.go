@@ -0,0 +1,66 @@
+package sub
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchCniConf(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	nc, err := loadNetConf(egressGWEntry, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchCniConf(ctx, defaultCniConfName, dir, nc)
+	}()
+
+	calico := `{"cniVersion": "0.3.1", "name": "k8s-pod-network", "plugins": [{"type": "calico"}]}`
+	path := filepath.Join(dir, "10-calico.conflist")
+	waitForChain := func() {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			data, err := os.ReadFile(path)
+			if err == nil && string(data) != calico {
+				types := pluginTypes(t, readConfList(t, path))
+				if !reflect.DeepEqual(types, []string{"calico", "egress-gw-cni"}) {
+					t.Fatalf("unexpected plugins: %v", types)
+				}
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("egress-gw-cni is not chained")
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	// the primary plugin installs its configuration.
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(path, []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChain()
+
+	// the primary plugin rewrites its configuration.
+	if err := os.WriteFile(path, []byte(calico), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForChain()
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Error(err)
+	}
+}
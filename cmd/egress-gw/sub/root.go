@@ -7,6 +7,8 @@ import (
 
 	"github.com/spf13/cobra"
 	egressgw "github.com/ysksuzuki/egress-gw-cni-plugin"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cilium"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -15,7 +17,13 @@ var config struct {
 	metricsAddr string
 	healthAddr  string
 	port        int
-	zapOpts     zap.Options
+	iface       string
+	natMode     string
+	ciliumCM    string
+
+	failOnCiliumConflict bool
+	labeledClientsOnly   bool
+	zapOpts              zap.Options
 }
 
 var rootCmd = &cobra.Command{
@@ -43,6 +51,11 @@ func init() {
 	pf.StringVar(&config.metricsAddr, "metrics-addr", ":8080", "bind address of metrics endpoint")
 	pf.StringVar(&config.healthAddr, "health-addr", ":8081", "bind address of health/readiness probes")
 	pf.IntVar(&config.port, "fou-port", 5555, "port number for foo-over-udp tunnels")
+	pf.StringVar(&config.iface, "interface", "", "network interface for outgoing packets; autodetected from the default route if empty")
+	pf.StringVar(&config.natMode, "nat-mode", string(cilium.NATModeAuto), "NAT mode: auto, masquerade, or snat")
+	pf.StringVar(&config.ciliumCM, "cilium-config", cilium.DefaultConfigNamespace+"/"+cilium.DefaultConfigName, "namespace/name of Cilium's ConfigMap; a name other than "+cilium.DefaultConfigName+" requires a matching rule in the egress-gw ClusterRole")
+	pf.BoolVar(&config.failOnCiliumConflict, "fail-on-cilium-conflict", false, "make the pod not ready while Cilium's settings conflict with the NAT mode")
+	pf.BoolVar(&config.labeledClientsOnly, "labeled-clients-only", false, "watch only client pods labeled with "+constants.LabelEgressClient+" by the webhook")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
@@ -1,19 +1,28 @@
 package sub
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"strings"
 	"time"
 
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/controllers"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cilium"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/founat"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/multus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -29,6 +38,7 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(egressv1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -45,6 +55,7 @@ func subMain() error {
 	if myName == "" {
 		return errors.New(constants.EnvEgressName + " environment variable must be set")
 	}
+	cluster := os.Getenv(constants.EnvClusterEgress) == "true"
 
 	myAddresses := strings.Split(os.Getenv(constants.EnvAddresses), ",")
 	if len(myAddresses) == 0 {
@@ -65,9 +76,30 @@ func subMain() error {
 
 	setupLog.Info("detected local IP addresses", "ipv4", ipv4.String(), "ipv6", ipv6.String())
 
+	natMode := cilium.NATMode(config.natMode)
+	switch natMode {
+	case cilium.NATModeAuto, cilium.NATModeMasquerade, cilium.NATModeSNAT:
+	default:
+		return fmt.Errorf("invalid NAT mode: %s", config.natMode)
+	}
+	ciliumKey, err := parseObjectKey(config.ciliumCM)
+	if err != nil {
+		return err
+	}
+
+	podCache := cache.ByObject{Transform: controllers.TrimPod}
+	if config.labeledClientsOnly {
+		podCache.Label = labels.SelectorFromSet(labels.Set{constants.LabelEgressClient: "true"})
+	}
+
 	timeout := gracefulTimeout
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                  scheme,
+		Scheme: scheme,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: podCache,
+			},
+		},
 		LeaderElection:          false,
 		MetricsBindAddress:      config.metricsAddr,
 		GracefulShutdownTimeout: &timeout,
@@ -84,17 +116,76 @@ func subMain() error {
 		return err
 	}
 
+	ciliumConfig, err := cilium.Load(context.Background(), mgr.GetAPIReader(), ciliumKey)
+	if err != nil {
+		return err
+	}
+	if ciliumConfig != nil {
+		setupLog.Info("detected Cilium", "bpf-masquerade", ciliumConfig.BPFMasquerade,
+			"host-legacy-routing", ciliumConfig.HostLegacyRouting, "routing-mode", ciliumConfig.RoutingMode)
+	}
+	natMode = ciliumConfig.NATMode(natMode)
+	setupLog.Info("selected NAT mode", "mode", natMode)
+	for _, msg := range ciliumConfig.Conflicts(natMode) {
+		setupLog.Error(errors.New(msg), "Cilium's setting conflicts with the NAT mode", "mode", natMode)
+	}
+	if config.failOnCiliumConflict {
+		if err := mgr.AddReadyzCheck("cilium", cilium.NewChecker(ciliumConfig, natMode)); err != nil {
+			return err
+		}
+	}
+
+	nextHops, err := parseNextHops(os.Getenv(constants.EnvNextHops))
+	if err != nil {
+		return err
+	}
+	opts := founat.EgressOptions{
+		SNAT:     natMode == cilium.NATModeSNAT,
+		NextHops: nextHops,
+	}
+	iface := config.iface
+	if iface == "" {
+		iface = os.Getenv(constants.EnvInterface)
+	}
+	if network := os.Getenv(constants.EnvNetwork); network != "" {
+		st, err := getNetworkStatus(context.Background(), mgr.GetAPIReader(), myNS, network)
+		if err != nil {
+			return err
+		}
+		opts.GatewayV4, opts.GatewayV6, err = st.Gateways()
+		if err != nil {
+			return err
+		}
+		if opts.GatewayV4 == nil && opts.GatewayV6 == nil {
+			return fmt.Errorf("no gateway found for network %s", st.Name)
+		}
+		setupLog.Info("detected network attachment", "network", st.Name, "interface", st.Interface,
+			"gateway-ipv4", opts.GatewayV4.String(), "gateway-ipv6", opts.GatewayV6.String())
+		iface = st.Interface
+	}
+	if iface == "" {
+		detected, err := founat.DefaultInterface()
+		if err != nil {
+			return fmt.Errorf("failed to detect the interface: %w", err)
+		}
+		iface = detected
+	}
+	setupLog.Info("using network interface", "interface", iface)
+	if err := controllers.SetupInterfaceMetrics(myNS, myName, iface); err != nil {
+		return err
+	}
+
 	ft := founat.NewFoUTunnel(0, config.port, ipv4, ipv6)
 	if err := ft.Init(); err != nil {
 		return err
 	}
 
-	eg := founat.NewEgress("eth0", ipv4, ipv6)
+	eg := founat.NewEgressWithOptions(iface, ipv4, ipv6, opts)
 	if err := eg.Init(); err != nil {
 		return err
 	}
 
-	if err := controllers.SetupPodWatcher(mgr, myNS, myName, ft, eg); err != nil {
+	if err := controllers.SetupPodWatcher(mgr, myNS, myName, cluster, ft, eg); err != nil {
 		return err
 	}
 
@@ -106,3 +197,52 @@ func subMain() error {
 
 	return nil
 }
+
+func parseObjectKey(s string) (client.ObjectKey, error) {
+	ns, name, ok := strings.Cut(s, "/")
+	if !ok || ns == "" || name == "" {
+		return client.ObjectKey{}, fmt.Errorf("invalid namespace/name: %s", s)
+	}
+	return client.ObjectKey{Namespace: ns, Name: name}, nil
+}
+
+func getNetworkStatus(ctx context.Context, r client.Reader, ns, network string) (*multus.NetworkStatus, error) {
+	podName := os.Getenv(constants.EnvPodName)
+	if podName == "" {
+		return nil, errors.New(constants.EnvPodName + " environment variable must be set")
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ns, Name: podName}, pod); err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", ns, podName, err)
+	}
+	return multus.FindNetworkStatus(pod, network)
+}
+
+// parseNextHops parses next hops in "DESTINATION=NEXTHOP,..." format.
+func parseNextHops(s string) ([]founat.NextHop, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var hops []founat.NextHop
+	for _, item := range strings.Split(s, ",") {
+		dst, gw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, errors.New(constants.EnvNextHops + " contains invalid next hop: " + item)
+		}
+		_, n, err := net.ParseCIDR(dst)
+		if err != nil {
+			return nil, errors.New(constants.EnvNextHops + " contains invalid destination: " + dst)
+		}
+		ip := net.ParseIP(gw)
+		if ip == nil {
+			return nil, errors.New(constants.EnvNextHops + " contains invalid address: " + gw)
+		}
+		if (n.IP.To4() != nil) != (ip.To4() != nil) {
+			return nil, errors.New(constants.EnvNextHops + " contains IP family mismatch: " + item)
+		}
+		hops = append(hops, founat.NextHop{Destination: n, Gateway: ip})
+	}
+	return hops, nil
+}
@@ -0,0 +1,41 @@
+package sub
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseNextHops(t *testing.T) {
+	hops, err := parseNextHops("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hops) != 0 {
+		t.Errorf("unexpected next hops: %v", hops)
+	}
+
+	hops, err = parseNextHops("10.1.0.0/16=192.0.2.1,fd02::/64=fd01::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hops) != 2 {
+		t.Fatalf("unexpected next hops: %v", hops)
+	}
+	if hops[0].Destination.String() != "10.1.0.0/16" || !hops[0].Gateway.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("unexpected next hop: %v", hops[0])
+	}
+	if hops[1].Destination.String() != "fd02::/64" || !hops[1].Gateway.Equal(net.ParseIP("fd01::1")) {
+		t.Errorf("unexpected next hop: %v", hops[1])
+	}
+
+	for _, s := range []string{
+		"10.1.0.0/16",
+		"10.1.0.0=192.0.2.1",
+		"10.1.0.0/16=192.0.2",
+		"10.1.0.0/16=fd01::1",
+	} {
+		if _, err := parseNextHops(s); err == nil {
+			t.Errorf("%q should be rejected", s)
+		}
+	}
+}
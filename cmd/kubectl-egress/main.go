@@ -0,0 +1,7 @@
+package main
+
+import "github.com/ysksuzuki/egress-gw-cni-plugin/cmd/kubectl-egress/sub"
+
+func main() {
+	sub.Execute()
+}
@@ -0,0 +1,106 @@
+package sub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/netinspect"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump POD",
+	Short: "dump links, routes and rules in the network namespace of a pod",
+	Long: `dump shows links, routes of all tables, and rules in the network
+namespace of a pod.  FoU links are marked with their remote gateway.
+
+The state is read by egress-gw-agent running on the node of the pod.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runDump(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dumpCmd)
+}
+
+func runDump(ctx context.Context, name string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	pod, err := getPod(ctx, c, name)
+	if err != nil {
+		return err
+	}
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s/%s has no IP address", pod.Namespace, pod.Name)
+	}
+	agent, err := agentPod(ctx, c, pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+
+	out, err := execAgent(ctx, agent, "inspect", "--pod-ip", pod.Status.PodIP)
+	if err != nil {
+		return err
+	}
+	st := &netinspect.State{}
+	if err := json.Unmarshal(out, st); err != nil {
+		return fmt.Errorf("failed to parse the output of egress-gw-agent: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LINKS")
+	fmt.Fprintln(w, "INDEX\tNAME\tTYPE\tSTATE\tFOU")
+	for _, l := range st.Links {
+		state := "DOWN"
+		if l.Up {
+			state = "UP"
+		}
+		fou := "-"
+		if l.EncapDport != 0 {
+			fou = fmt.Sprintf("remote %s port %d", l.Remote, l.EncapDport)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", l.Index, l.Name, l.Type, state, fou)
+	}
+
+	fmt.Fprintln(w, "\nRULES")
+	fmt.Fprintln(w, "PRIORITY\tFROM\tTO\tFWMARK\tTABLE")
+	for _, r := range st.Rules {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", r.Priority, orAll(r.Src), orAll(r.Dst), markString(r.Mark), r.Table)
+	}
+
+	fmt.Fprintln(w, "\nROUTES")
+	fmt.Fprintln(w, "TABLE\tDESTINATION\tGATEWAY\tDEV\tPROTOCOL")
+	for _, r := range st.Routes {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", r.Table, r.Dst, orDash(r.Gateway), orDash(r.Dev), r.Protocol)
+	}
+	return w.Flush()
+}
+
+func orAll(s string) string {
+	if s == "" {
+		return "all"
+	}
+	return s
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func markString(mark uint32) string {
+	if mark == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("0x%x", mark)
+}
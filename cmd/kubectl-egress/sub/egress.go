@@ -0,0 +1,138 @@
+package sub
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// egressInfo is a common view of Egress and ClusterEgress.
+type egressInfo struct {
+	kind string
+	// key has no namespace for ClusterEgress.
+	key client.ObjectKey
+	// namespace is where egress pods run.
+	namespace string
+	// name is the name of the egress pods' Deployment and Service.
+	name    string
+	spec    *egressv1.EgressSpec
+	status  *egressv1.EgressStatus
+	allowed func(*corev1.Namespace) (bool, error)
+}
+
+func (e *egressInfo) String() string {
+	if e.key.Namespace == "" {
+		return e.kind + " " + e.key.Name
+	}
+	return e.kind + " " + e.key.String()
+}
+
+// egressRefs returns the keys of Egresses and ClusterEgresses referenced
+// by the annotations of the pod, along with the annotation keys.
+func egressRefs(pod *corev1.Pod) map[client.ObjectKey]string {
+	refs := make(map[client.ObjectKey]string)
+	for k, v := range pod.Annotations {
+		var ns string
+		switch {
+		case k == constants.AnnClusterEgress:
+		case strings.HasPrefix(k, constants.AnnEgressPrefix):
+			ns = k[len(constants.AnnEgressPrefix):]
+		default:
+			continue
+		}
+		for _, name := range strings.Split(v, ",") {
+			refs[client.ObjectKey{Namespace: ns, Name: name}] = k
+		}
+	}
+	return refs
+}
+
+func sortedKeys(refs map[client.ObjectKey]string) []client.ObjectKey {
+	keys := make([]client.ObjectKey, 0, len(refs))
+	for k := range refs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// getEgress returns Egress or ClusterEgress for the key.
+// It returns nil if not found.
+func getEgress(ctx context.Context, c client.Client, key client.ObjectKey) (*egressInfo, error) {
+	if key.Namespace == "" {
+		ce := &egressv1.ClusterEgress{}
+		if err := c.Get(ctx, key, ce); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return clusterEgressInfo(ce), nil
+	}
+
+	eg := &egressv1.Egress{}
+	if err := c.Get(ctx, key, eg); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return namespacedEgressInfo(eg), nil
+}
+
+func namespacedEgressInfo(eg *egressv1.Egress) *egressInfo {
+	return &egressInfo{
+		kind:      "Egress",
+		key:       client.ObjectKeyFromObject(eg),
+		namespace: eg.Namespace,
+		name:      eg.Name,
+		spec:      &eg.Spec,
+		status:    &eg.Status,
+		allowed:   eg.IsNamespaceAllowed,
+	}
+}
+
+func clusterEgressInfo(ce *egressv1.ClusterEgress) *egressInfo {
+	return &egressInfo{
+		kind:      "ClusterEgress",
+		key:       client.ObjectKey{Name: ce.Name},
+		namespace: ce.Spec.Namespace,
+		name:      ce.ResourceName(),
+		spec:      &ce.Spec.EgressSpec,
+		status:    &ce.Status,
+		allowed:   ce.IsNamespaceAllowed,
+	}
+}
+
+// gateway returns the ClusterIP of the Service for egress pods.
+func gateway(ctx context.Context, c client.Client, e *egressInfo) (string, error) {
+	svc := &corev1.Service{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: e.namespace, Name: e.name}, svc)
+	if apierrors.IsNotFound(err) {
+		return "<none>", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return svc.Spec.ClusterIP, nil
+}
+
+// destinationFor returns the destination of the Egress including `ip`.
+func destinationFor(e *egressInfo, ip net.IP) string {
+	best := ""
+	bestOnes := -1
+	for _, d := range e.spec.Destinations {
+		_, n, err := net.ParseCIDR(d.CIDR)
+		if err != nil || !n.Contains(ip) {
+			continue
+		}
+		if ones, _ := n.Mask.Size(); ones > bestOnes {
+			best = d.CIDR
+			bestOnes = ones
+		}
+	}
+	return best
+}
@@ -0,0 +1,83 @@
+package sub
+
+import (
+	"net"
+	"testing"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEgressRefs(t *testing.T) {
+	pod := &corev1.Pod{}
+	pod.Annotations = map[string]string{
+		"egress.ysksuzuki.com/internet": "egress,egress2",
+		"egress.ysksuzuki.com":          "global",
+		"example.com/foo":               "bar",
+	}
+
+	refs := egressRefs(pod)
+	expected := map[client.ObjectKey]string{
+		{Namespace: "internet", Name: "egress"}:  "egress.ysksuzuki.com/internet",
+		{Namespace: "internet", Name: "egress2"}: "egress.ysksuzuki.com/internet",
+		{Name: "global"}:                         "egress.ysksuzuki.com",
+	}
+	if len(refs) != len(expected) {
+		t.Fatalf("unexpected refs: %v", refs)
+	}
+	for k, v := range expected {
+		if refs[k] != v {
+			t.Errorf("unexpected annotation for %s: %s", k, refs[k])
+		}
+	}
+
+	keys := sortedKeys(refs)
+	if keys[0].Name != "global" || keys[1].Name != "egress" || keys[2].Name != "egress2" {
+		t.Errorf("unexpected order: %v", keys)
+	}
+}
+
+func TestDestinationFor(t *testing.T) {
+	e := &egressInfo{spec: &egressv1.EgressSpec{
+		Destinations: []egressv1.EgressDestination{
+			{CIDR: "0.0.0.0/0"},
+			{CIDR: "192.168.0.0/16"},
+			{CIDR: "fd02::/64"},
+		},
+	}}
+
+	testCases := map[string]string{
+		"9.9.9.9":     "0.0.0.0/0",
+		"192.168.1.1": "192.168.0.0/16",
+		"fd02::1":     "fd02::/64",
+		"fd03::1":     "",
+	}
+	for ip, expected := range testCases {
+		if actual := destinationFor(e, net.ParseIP(ip)); actual != expected {
+			t.Errorf("%s: expected %q, actual %q", ip, expected, actual)
+		}
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	ip, err := parseSource("source: 10.20.0.214:50416\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.ParseIP("10.20.0.214")) {
+		t.Errorf("unexpected source: %s", ip)
+	}
+
+	ip, err = parseSource("source: [fd02::1]:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.ParseIP("fd02::1")) {
+		t.Errorf("unexpected source: %s", ip)
+	}
+
+	if _, err := parseSource("hello"); err == nil {
+		t.Error("unexpected response should be an error")
+	}
+}
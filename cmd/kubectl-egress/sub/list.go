@@ -0,0 +1,93 @@
+package sub
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var listAllNamespaces bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list Egresses and ClusterEgresses with their gateways and clients",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cmd.SilenceUsage = true
+		return runList(cmd.Context())
+	},
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(&listAllNamespaces, "all-namespaces", "A", false, "list Egresses in all namespaces")
+	rootCmd.AddCommand(listCmd)
+}
+
+func runList(ctx context.Context) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var opts []client.ListOption
+	if !listAllNamespaces {
+		ns, err := namespace()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, client.InNamespace(ns))
+	}
+
+	var egresses []*egressInfo
+	egList := &egressv1.EgressList{}
+	if err := c.List(ctx, egList, opts...); err != nil {
+		return err
+	}
+	for i := range egList.Items {
+		egresses = append(egresses, namespacedEgressInfo(&egList.Items[i]))
+	}
+	ceList := &egressv1.ClusterEgressList{}
+	if err := c.List(ctx, ceList); err != nil {
+		return err
+	}
+	for i := range ceList.Items {
+		egresses = append(egresses, clusterEgressInfo(&ceList.Items[i]))
+	}
+
+	// clients may run in any namespace.
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods); err != nil {
+		return err
+	}
+	clients := make(map[client.ObjectKey]int)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.HostNetwork || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for k := range egressRefs(pod) {
+			clients[k]++
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tGATEWAY\tDESTINATIONS\tREADY\tCLIENTS")
+	for _, e := range egresses {
+		gw, err := gateway(ctx, c, e)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%d\n",
+			e.kind, e.namespace, e.key.Name, gw,
+			strings.Join(e.spec.ClientDestinations(), ","),
+			e.status.ReadyReplicas, e.spec.Replicas, clients[e.key])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,97 @@
+package sub
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var podCmd = &cobra.Command{
+	Use:   "pod NAME",
+	Short: "show which Egresses a pod uses and why",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runPod(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(podCmd)
+}
+
+func getPod(ctx context.Context, c client.Client, name string) (*corev1.Pod, error) {
+	ns, err := namespace()
+	if err != nil {
+		return nil, err
+	}
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func runPod(ctx context.Context, name string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	pod, err := getPod(ctx, c, name)
+	if err != nil {
+		return err
+	}
+
+	refs := egressRefs(pod)
+	if len(refs) == 0 {
+		fmt.Printf("pod %s/%s does not use egress\n", pod.Namespace, pod.Name)
+		return nil
+	}
+	if pod.Spec.HostNetwork {
+		fmt.Printf("pod %s/%s runs in the host network and cannot use egress\n", pod.Namespace, pod.Name)
+		return nil
+	}
+
+	podNS := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: pod.Namespace}, podNS); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "EGRESS\tANNOTATION\tGATEWAY\tDESTINATIONS\tSTATUS")
+	for _, key := range sortedKeys(refs) {
+		e, err := getEgress(ctx, c, key)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			kind := "Egress " + key.String()
+			if key.Namespace == "" {
+				kind = "ClusterEgress " + key.Name
+			}
+			fmt.Fprintf(w, "%s\t%s\t-\t-\tnot found\n", kind, refs[key])
+			continue
+		}
+
+		status := "used"
+		allowed, err := e.allowed(podNS)
+		switch {
+		case err != nil:
+			status = "invalid allowedNamespaces: " + err.Error()
+		case !allowed:
+			status = "denied by allowedNamespaces"
+		}
+		gw, err := gateway(ctx, c, e)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e, refs[key], gw, strings.Join(e.spec.ClientDestinations(), ","), status)
+	}
+	return w.Flush()
+}
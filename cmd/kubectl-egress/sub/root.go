@@ -0,0 +1,133 @@
+package sub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	egressgw "github.com/ysksuzuki/egress-gw-cni-plugin"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const agentSelector = "app.kubernetes.io/component=egress-gw-agent"
+
+var config struct {
+	kubeconfig     string
+	context        string
+	namespace      string
+	agentNamespace string
+	kubectl        string
+}
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(egressv1.AddToScheme(scheme))
+}
+
+var rootCmd = &cobra.Command{
+	Use:   "kubectl-egress",
+	Short: "inspect and debug egress",
+	Long: `kubectl-egress is a kubectl plugin to inspect and debug egress.
+
+Install it in PATH and run it as "kubectl egress".`,
+	Version: egressgw.Version(),
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	pf := rootCmd.PersistentFlags()
+	pf.StringVar(&config.kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	pf.StringVar(&config.context, "context", "", "the name of the kubeconfig context to use")
+	pf.StringVarP(&config.namespace, "namespace", "n", "", "namespace of the target resources")
+	pf.StringVar(&config.agentNamespace, "agent-namespace", "kube-system", "namespace of egress-gw-agent pods")
+	pf.StringVar(&config.kubectl, "kubectl", "kubectl", "kubectl command to run commands in egress-gw-agent pods")
+}
+
+func clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = config.kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: config.context})
+}
+
+func newClient() (client.Client, error) {
+	cfg, err := clientConfig().ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+// namespace returns the namespace given by the flag or the current context.
+func namespace() (string, error) {
+	if config.namespace != "" {
+		return config.namespace, nil
+	}
+	ns, _, err := clientConfig().Namespace()
+	return ns, err
+}
+
+// agentPod returns the egress-gw-agent pod running on the node.
+func agentPod(ctx context.Context, c client.Client, node string) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	sel, err := labels.Parse(agentSelector)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.List(ctx, pods, client.InNamespace(config.agentNamespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == node && pod.Status.Phase == corev1.PodRunning {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("no running egress-gw-agent on node %s", node)
+}
+
+// execAgent runs egress-gw-agent with `args` in the agent pod.
+func execAgent(ctx context.Context, agent *corev1.Pod, args ...string) ([]byte, error) {
+	kargs := []string{"exec", "-n", agent.Namespace, agent.Name, "-c", "egress-gw-agent", "--"}
+	if config.kubeconfig != "" {
+		kargs = append([]string{"--kubeconfig", config.kubeconfig}, kargs...)
+	}
+	if config.context != "" {
+		kargs = append([]string{"--context", config.context}, kargs...)
+	}
+	kargs = append(kargs, "egress-gw-agent")
+	kargs = append(kargs, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, config.kubectl, kargs...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := bytes.TrimSpace(stderr.Bytes())
+		if len(msg) == 0 {
+			return nil, err
+		}
+		return nil, errors.New(string(msg))
+	}
+	return stdout.Bytes(), nil
+}
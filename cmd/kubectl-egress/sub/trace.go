@@ -0,0 +1,154 @@
+package sub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var traceConfig struct {
+	url string
+}
+
+var traceCmd = &cobra.Command{
+	Use:   "trace POD",
+	Short: "send a probe from a pod and report the observed source address",
+	Long: `trace sends an HTTP request from the network namespace of a pod
+to an echo server and reports the source address observed by the server.
+
+The echo server should respond with "source: <address>:<port>" like
+e2e/echo-server does for the "/source" path.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runTrace(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	traceCmd.Flags().StringVar(&traceConfig.url, "url", "", "URL of the echo server, e.g. http://9.9.9.9/source")
+	traceCmd.MarkFlagRequired("url")
+	rootCmd.AddCommand(traceCmd)
+}
+
+func runTrace(ctx context.Context, name string) error {
+	u, err := url.Parse(traceConfig.url)
+	if err != nil {
+		return err
+	}
+	dst := net.ParseIP(u.Hostname())
+	if dst == nil {
+		return errors.New("the host of --url must be an IP address: " + u.Hostname())
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	pod, err := getPod(ctx, c, name)
+	if err != nil {
+		return err
+	}
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s/%s has no IP address", pod.Namespace, pod.Name)
+	}
+
+	// find the egress expected to handle the destination.
+	var expected *egressInfo
+	for _, key := range sortedKeys(egressRefs(pod)) {
+		e, err := getEgress(ctx, c, key)
+		if err != nil {
+			return err
+		}
+		if e != nil && destinationFor(e, dst) != "" {
+			expected = e
+			break
+		}
+	}
+	if expected != nil {
+		fmt.Printf("expected egress: %s (destination %s)\n", expected, destinationFor(expected, dst))
+	} else {
+		fmt.Println("expected egress: none")
+	}
+
+	agent, err := agentPod(ctx, c, pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+	out, err := execAgent(ctx, agent, "probe", "--pod-ip", pod.Status.PodIP, "--url", traceConfig.url)
+	if err != nil {
+		return err
+	}
+	source, err := parseSource(string(out))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("observed source: %s\n", source)
+
+	via, err := whoHas(ctx, c, source, expected)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("source belongs to: %s\n", via)
+	return nil
+}
+
+// parseSource parses the response of the echo server.
+func parseSource(body string) (net.IP, error) {
+	line := strings.TrimSpace(body)
+	addr, ok := strings.CutPrefix(line, "source: ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected response from the echo server: %q", line)
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid source address: %s", host)
+	}
+	return ip, nil
+}
+
+// whoHas describes the pod or node having the address.
+func whoHas(ctx context.Context, c client.Client, ip net.IP, expected *egressInfo) (string, error) {
+	if expected != nil {
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(expected.namespace), client.MatchingLabels{
+			constants.LabelAppName:      "egress-cni",
+			constants.LabelAppInstance:  expected.name,
+			constants.LabelAppComponent: "egress",
+		}); err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			for _, podIP := range pod.Status.PodIPs {
+				if net.ParseIP(podIP.IP).Equal(ip) {
+					return fmt.Sprintf("egress pod %s/%s of %s", pod.Namespace, pod.Name, expected), nil
+				}
+			}
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return "", err
+	}
+	for _, node := range nodes.Items {
+		for _, a := range node.Status.Addresses {
+			if net.ParseIP(a.Address).Equal(ip) {
+				return "node " + node.Name, nil
+			}
+		}
+	}
+	return "unknown (outside of the cluster)", nil
+}
@@ -2,7 +2,7 @@ package controllers
 
 import (
 	"context"
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	"sort"
 
@@ -17,7 +17,7 @@ import (
 )
 
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;update;patch
-// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses;clusteregresses,verbs=get;list;watch
 
 // SetupCRBReconciler setups ClusterResourceBinding reconciler for egress-controller.
 func SetupCRBReconciler(mgr manager.Manager) error {
@@ -64,15 +64,23 @@ func reconcileCRB(ctx context.Context, cl client.Client, log logr.Logger, name s
 		return err
 	}
 
-	egresses := &egressv1beta1.EgressList{}
+	egresses := &egressv1.EgressList{}
 	if err := cl.List(ctx, egresses); err != nil {
 		return err
 	}
 
+	cegresses := &egressv1.ClusterEgressList{}
+	if err := cl.List(ctx, cegresses); err != nil {
+		return err
+	}
+
 	nsMap := make(map[string]struct{})
 	for _, eg := range egresses.Items {
 		nsMap[eg.Namespace] = struct{}{}
 	}
+	for _, ce := range cegresses.Items {
+		nsMap[ce.Spec.Namespace] = struct{}{}
+	}
 
 	namespaces := make([]string, 0, len(nsMap))
 	for k := range nsMap {
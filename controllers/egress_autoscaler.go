@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/expfmt"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// autoscaleInterval is the interval to re-calculate the desired replicas.
+	autoscaleInterval = 30 * time.Second
+
+	// scaleDownStabilization is the window in which the highest recommendation
+	// is used to prevent replicas from flapping.
+	scaleDownStabilization = 5 * time.Minute
+
+	metricClientPods         = constants.MetricsNS + "_egress_client_pod_count"
+	metricInterfaceBytes     = constants.MetricsNS + "_egress_interface_bytes_total"
+	defaultEgressMetricsPort = 8080
+)
+
+// PodMetrics is a snapshot of the metrics of an egress pod used for autoscaling.
+type PodMetrics struct {
+	// Clients is the number of client pods using the egress pod.
+	Clients int
+
+	// Bytes is the cumulative number of bytes through the egress interface.
+	Bytes float64
+}
+
+// PodMetricsFetcher fetches metrics from egress pods.
+type PodMetricsFetcher interface {
+	Fetch(ctx context.Context, pod *corev1.Pod) (*PodMetrics, error)
+}
+
+// NewHTTPMetricsFetcher returns a PodMetricsFetcher that scrapes the "metrics"
+// port of egress pods.
+func NewHTTPMetricsFetcher(timeout time.Duration) PodMetricsFetcher {
+	return &httpMetricsFetcher{client: &http.Client{Timeout: timeout}}
+}
+
+type httpMetricsFetcher struct {
+	client *http.Client
+}
+
+func (f *httpMetricsFetcher) Fetch(ctx context.Context, pod *corev1.Pod) (*PodMetrics, error) {
+	port := defaultEgressMetricsPort
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if c.Name == egressv1.EgressContainerName && p.Name == "metrics" {
+				port = int(p.ContainerPort)
+			}
+		}
+	}
+
+	url := "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)) + "/metrics"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics from %s: %w", url, err)
+	}
+
+	m := &PodMetrics{}
+	if mf, ok := families[metricClientPods]; ok {
+		for _, metric := range mf.Metric {
+			m.Clients += int(metric.GetGauge().GetValue())
+		}
+	}
+	if mf, ok := families[metricInterfaceBytes]; ok {
+		for _, metric := range mf.Metric {
+			m.Bytes += metric.GetCounter().GetValue()
+		}
+	}
+	return m, nil
+}
+
+type bytesSample struct {
+	bytes float64
+	time  time.Time
+}
+
+type recommendation struct {
+	replicas int32
+	time     time.Time
+}
+
+// autoscaler keeps the state to calculate the desired replicas of Egresses.
+type autoscaler struct {
+	fetcher PodMetricsFetcher
+
+	mu      sync.Mutex
+	samples map[string]map[types.UID]bytesSample
+	history map[string][]recommendation
+}
+
+func newAutoscaler(fetcher PodMetricsFetcher) *autoscaler {
+	return &autoscaler{
+		fetcher: fetcher,
+		samples: make(map[string]map[types.UID]bytesSample),
+		history: make(map[string][]recommendation),
+	}
+}
+
+func (a *autoscaler) forget(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.samples, key)
+	delete(a.history, key)
+}
+
+// recommend returns the desired replicas for the Egress identified by `key`.
+// It returns false if metrics are not sufficient yet.
+func (a *autoscaler) recommend(ctx context.Context, log logr.Logger, key string, as *egressv1.EgressAutoscaling, pods []corev1.Pod, now time.Time) (int32, bool) {
+	var clients int
+	var rate float64
+	measured := false
+	hasRate := false
+	current := make(map[types.UID]bytesSample)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		m, err := a.fetcher.Fetch(ctx, pod)
+		if err != nil {
+			log.Error(err, "failed to fetch metrics", "pod", pod.Name)
+			continue
+		}
+		measured = true
+		if m.Clients > clients {
+			// all egress pods serve the same set of client pods.
+			clients = m.Clients
+		}
+		current[pod.UID] = bytesSample{bytes: m.Bytes, time: now}
+	}
+	if !measured {
+		return 0, false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for uid, cur := range current {
+		prev, ok := a.samples[key][uid]
+		if !ok || !cur.time.After(prev.time) || cur.bytes < prev.bytes {
+			continue
+		}
+		hasRate = true
+		rate += (cur.bytes - prev.bytes) / cur.time.Sub(prev.time).Seconds()
+	}
+	a.samples[key] = current
+	if as.TargetBytesPerSecondPerReplica != nil && !hasRate {
+		// wait for the next round to calculate the throughput.
+		return 0, false
+	}
+
+	desired := desiredReplicas(as, clients, rate)
+
+	// scale down only when all recommendations in the window are lower.
+	history := []recommendation{{replicas: desired, time: now}}
+	for _, r := range a.history[key] {
+		if now.Sub(r.time) >= scaleDownStabilization {
+			continue
+		}
+		history = append(history, r)
+		if r.replicas > desired {
+			desired = r.replicas
+		}
+	}
+	a.history[key] = history
+
+	return desired, true
+}
+
+// desiredReplicas calculates the number of replicas to meet the targets.
+func desiredReplicas(as *egressv1.EgressAutoscaling, clients int, bytesPerSecond float64) int32 {
+	desired := as.MinReplicas
+	if desired < 1 {
+		desired = 1
+	}
+
+	if t := as.TargetClientsPerReplica; t != nil && *t > 0 {
+		n := int32(math.Ceil(float64(clients) / float64(*t)))
+		if n > desired {
+			desired = n
+		}
+	}
+	if t := as.TargetBytesPerSecondPerReplica; t != nil {
+		if target := t.AsApproximateFloat64(); target > 0 {
+			n := int32(math.Min(math.Ceil(bytesPerSecond/target), math.MaxInt32))
+			if n > desired {
+				desired = n
+			}
+		}
+	}
+
+	if desired > as.MaxReplicas {
+		desired = as.MaxReplicas
+	}
+	return desired
+}
+
+func (r *EgressReconciler) autoscale(ctx context.Context, log logr.Logger, eg *egressObject) (bool, error) {
+	key := client.ObjectKeyFromObject(eg.Object).String()
+	as := eg.spec.Autoscaling
+	if r.autoscaler == nil {
+		return false, nil
+	}
+	if as == nil {
+		r.autoscaler.forget(key)
+		return false, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(eg.namespace), client.MatchingLabels(selectorLabels(eg.name))); err != nil {
+		return false, err
+	}
+
+	desired, ok := r.autoscaler.recommend(ctx, log, key, as, pods.Items, time.Now())
+	if !ok || desired == eg.spec.Replicas {
+		return true, nil
+	}
+
+	orig := eg.Object.DeepCopyObject().(client.Object)
+	log.Info("autoscaling", "from", eg.spec.Replicas, "to", desired)
+	eg.spec.Replicas = desired
+	if err := r.Patch(ctx, eg.Object, client.MergeFrom(orig)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestDesiredReplicas(t *testing.T) {
+	testCases := []struct {
+		name     string
+		as       egressv1.EgressAutoscaling
+		clients  int
+		rate     float64
+		expected int32
+	}{
+		{
+			name:     "min replicas",
+			as:       egressv1.EgressAutoscaling{MinReplicas: 2, MaxReplicas: 5, TargetClientsPerReplica: pointer.Int32(10)},
+			clients:  3,
+			expected: 2,
+		},
+		{
+			name:     "clients",
+			as:       egressv1.EgressAutoscaling{MinReplicas: 1, MaxReplicas: 5, TargetClientsPerReplica: pointer.Int32(10)},
+			clients:  21,
+			expected: 3,
+		},
+		{
+			name:     "throughput",
+			as:       egressv1.EgressAutoscaling{MinReplicas: 1, MaxReplicas: 5, TargetBytesPerSecondPerReplica: resource.NewQuantity(1000, resource.DecimalSI)},
+			rate:     3500,
+			expected: 4,
+		},
+		{
+			name: "larger of targets",
+			as: egressv1.EgressAutoscaling{MinReplicas: 1, MaxReplicas: 5,
+				TargetClientsPerReplica:        pointer.Int32(10),
+				TargetBytesPerSecondPerReplica: resource.NewQuantity(1000, resource.DecimalSI)},
+			clients:  15,
+			rate:     100,
+			expected: 2,
+		},
+		{
+			name:     "max replicas",
+			as:       egressv1.EgressAutoscaling{MinReplicas: 1, MaxReplicas: 5, TargetClientsPerReplica: pointer.Int32(1)},
+			clients:  100,
+			expected: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := desiredReplicas(&tc.as, tc.clients, tc.rate)
+			if actual != tc.expected {
+				t.Errorf("expected %d, actual %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+type fakeFetcher map[types.UID]*PodMetrics
+
+func (f fakeFetcher) Fetch(ctx context.Context, pod *corev1.Pod) (*PodMetrics, error) {
+	m, ok := f[pod.UID]
+	if !ok {
+		return nil, fmt.Errorf("no metrics for %s", pod.Name)
+	}
+	return m, nil
+}
+
+func makeEgressPod(uid string) corev1.Pod {
+	pod := corev1.Pod{}
+	pod.Name = uid
+	pod.UID = types.UID(uid)
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.PodIP = "10.0.0.1"
+	return pod
+}
+
+func TestAutoscalerRecommend(t *testing.T) {
+	ctx := context.Background()
+	as := &egressv1.EgressAutoscaling{
+		MinReplicas:                    1,
+		MaxReplicas:                    10,
+		TargetBytesPerSecondPerReplica: resource.NewQuantity(1000, resource.DecimalSI),
+	}
+	pods := []corev1.Pod{makeEgressPod("a"), makeEgressPod("b")}
+	fetcher := fakeFetcher{
+		"a": {Bytes: 0},
+		"b": {Bytes: 0},
+	}
+	a := newAutoscaler(fetcher)
+	now := time.Now()
+
+	if _, ok := a.recommend(ctx, logr.Discard(), "ns/eg", as, pods, now); ok {
+		t.Fatal("recommendation without throughput")
+	}
+
+	now = now.Add(10 * time.Second)
+	fetcher["a"].Bytes = 20000
+	fetcher["b"].Bytes = 30000
+	replicas, ok := a.recommend(ctx, logr.Discard(), "ns/eg", as, pods, now)
+	if !ok {
+		t.Fatal("no recommendation")
+	}
+	if replicas != 5 {
+		t.Errorf("expected 5, actual %d", replicas)
+	}
+
+	// scale down is stabilized.
+	now = now.Add(10 * time.Second)
+	replicas, _ = a.recommend(ctx, logr.Discard(), "ns/eg", as, pods, now)
+	if replicas != 5 {
+		t.Errorf("expected 5, actual %d", replicas)
+	}
+
+	now = now.Add(scaleDownStabilization)
+	replicas, _ = a.recommend(ctx, logr.Discard(), "ns/eg", as, pods, now)
+	if replicas != 1 {
+		t.Errorf("expected 1, actual %d", replicas)
+	}
+
+	delete(fetcher, "a")
+	delete(fetcher, "b")
+	if _, ok := a.recommend(ctx, logr.Discard(), "ns/eg", as, pods, now); ok {
+		t.Error("recommendation without metrics")
+	}
+}
+
+func TestHTTPMetricsFetcher(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `# HELP egressgw_egress_client_pod_count the number of client pods which use this egress
+# TYPE egressgw_egress_client_pod_count gauge
+egressgw_egress_client_pod_count{egress="eg",namespace="ns"} 12
+# HELP egressgw_egress_interface_bytes_total the number of bytes received or transmitted by the egress interface
+# TYPE egressgw_egress_interface_bytes_total counter
+egressgw_egress_interface_bytes_total{direction="receive",egress="eg",namespace="ns"} 1000
+egressgw_egress_interface_bytes_total{direction="transmit",egress="eg",namespace="ns"} 234
+`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &corev1.Pod{}
+	pod.Status.PodIP = host
+	pod.Spec.Containers = []corev1.Container{{
+		Name:  "egress-gw",
+		Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: int32(port)}},
+	}}
+
+	m, err := NewHTTPMetricsFetcher(time.Second).Fetch(context.Background(), pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Clients != 12 {
+		t.Errorf("unexpected clients: %d", m.Clients)
+	}
+	if m.Bytes != 1234 {
+		t.Errorf("unexpected bytes: %f", m.Bytes)
+	}
+}
@@ -2,13 +2,18 @@ package controllers
 
 import (
 	"context"
-	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/multus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,43 +22,87 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// EgressReconciler reconciles a Egress object
+// EgressReconciler reconciles Egress and ClusterEgress objects
 type EgressReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Image  string
 	Port   int32
+
+	// Metrics fetches metrics from egress pods for autoscaling.
+	// If nil, autoscaling is disabled.
+	Metrics PodMetricsFetcher
+
+	// NetworkPolicy is the kind of network policy generated for egress pods.
+	// If empty, no network policy is generated.
+	NetworkPolicy NetworkPolicyMode
+
+	autoscaler    *autoscaler
+	networkPolicy NetworkPolicyMode
 }
 
-// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses,verbs=get;list;watch
-// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses;clusteregresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses/status;clusteregresses/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=services;serviceaccounts,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumnetworkpolicies,verbs=get;list;watch;create;update;patch
+
+// egress-controller reads the endpoints of the API server to allow egress pods to access it.
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 
 // egress-controller needs to have access to Pods to grant egress service accounts the same privilege.
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
+// egressObject is a common view of Egress and ClusterEgress.
+// Resources for the object are created in `namespace` with `name`.
+type egressObject struct {
+	client.Object
+	namespace string
+	name      string
+	cluster   bool
+	spec      *egressv1.EgressSpec
+	status    *egressv1.EgressStatus
+}
+
 // Reconcile implements Reconciler interface.
+// A request without namespace is for ClusterEgress.
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.1/pkg/reconcile
 func (r *EgressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	eg := &egressv1beta1.Egress{}
-	if err := r.Get(ctx, req.NamespacedName, eg); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{}, nil
+	var eg *egressObject
+	if req.Namespace == "" {
+		ce := &egressv1.ClusterEgress{}
+		if err := r.Get(ctx, req.NamespacedName, ce); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
+			logger.Error(err, "failed to get cluster egress")
+			return ctrl.Result{}, err
 		}
-		logger.Error(err, "failed to get egress")
-		return ctrl.Result{}, err
+		eg = &egressObject{Object: ce, namespace: ce.Spec.Namespace, name: ce.ResourceName(), cluster: true, spec: &ce.Spec.EgressSpec, status: &ce.Status}
+	} else {
+		e := &egressv1.Egress{}
+		if err := r.Get(ctx, req.NamespacedName, e); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
+			logger.Error(err, "failed to get egress")
+			return ctrl.Result{}, err
+		}
+		eg = &egressObject{Object: e, namespace: e.Namespace, name: e.Name, spec: &e.Spec, status: &e.Status}
 	}
-	if eg.DeletionTimestamp != nil {
+	if eg.GetDeletionTimestamp() != nil {
 		return ctrl.Result{}, nil
 	}
 
-	if err := r.reconcileServiceAccount(ctx, logger, req.Namespace); err != nil {
+	if err := r.reconcileServiceAccount(ctx, logger, eg.namespace); err != nil {
 		logger.Error(err, "failed to reconcile service account")
 		return ctrl.Result{}, err
 	}
@@ -64,6 +113,12 @@ func (r *EgressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	autoscaling, err := r.autoscale(ctx, logger, eg)
+	if err != nil {
+		logger.Error(err, "failed to autoscale")
+		return ctrl.Result{}, err
+	}
+
 	if err := r.reconcileDeployment(ctx, logger, eg); err != nil {
 		logger.Error(err, "failed to reconcile deployment")
 		return ctrl.Result{}, err
@@ -74,11 +129,24 @@ func (r *EgressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcilePDB(ctx, logger, eg); err != nil {
+		logger.Error(err, "failed to reconcile pod disruption budget")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileNetworkPolicy(ctx, logger, eg); err != nil {
+		logger.Error(err, "failed to reconcile network policy")
+		return ctrl.Result{}, err
+	}
+
 	if err := r.updateStatus(ctx, logger, eg); err != nil {
 		logger.Error(err, "failed to update status")
 		return ctrl.Result{}, err
 	}
 
+	if autoscaling {
+		return ctrl.Result{RequeueAfter: autoscaleInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -105,14 +173,14 @@ func selectorLabels(name string) map[string]string {
 	}
 }
 
-func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *appsv1.Deployment) {
+func (r *EgressReconciler) reconcilePodTemplate(eg *egressObject, depl *appsv1.Deployment) {
 	target := &depl.Spec.Template
 	target.Labels = make(map[string]string)
 	if target.Annotations == nil {
 		target.Annotations = make(map[string]string)
 	}
 
-	desired := eg.Spec.Template
+	desired := eg.spec.Template
 	podSpec := &corev1.PodSpec{}
 	if desired != nil {
 		podSpec = desired.Spec.DeepCopy()
@@ -123,16 +191,20 @@ func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *
 			target.Labels[k] = v
 		}
 	}
-	for k, v := range selectorLabels(eg.Name) {
+	for k, v := range selectorLabels(eg.name) {
 		target.Labels[k] = v
 	}
+	if eg.spec.NetworkAttachment != "" {
+		target.Annotations[multus.AnnNetworks] = multus.NetworksAnnotation(eg.spec.NetworkAttachment, eg.spec.Interface)
+	}
 
+	r.addTopologySpread(eg, podSpec)
 	podSpec.ServiceAccountName = constants.SAEgress
 	podSpec.Volumes = r.addVolumes(podSpec.Volumes)
 
 	var egressContainer *corev1.Container
 	for i := range podSpec.Containers {
-		if podSpec.Containers[i].Name != "egress-gw" {
+		if podSpec.Containers[i].Name != egressv1.EgressContainerName {
 			continue
 		}
 		egressContainer = &(podSpec.Containers[i])
@@ -141,7 +213,7 @@ func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *
 		podSpec.Containers = append([]corev1.Container{{}}, podSpec.Containers...)
 		egressContainer = &(podSpec.Containers[0])
 	}
-	egressContainer.Name = "egress-gw"
+	egressContainer.Name = egressv1.EgressContainerName
 	if egressContainer.Image == "" {
 		egressContainer.Image = r.Image
 	}
@@ -151,14 +223,14 @@ func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *
 	if len(egressContainer.Args) == 0 {
 		egressContainer.Args = []string{"--zap-stacktrace-level=panic"}
 	}
-	egressContainer.Env = append(egressContainer.Env,
+	egressContainer.Env = setEnv(egressContainer.Env,
 		corev1.EnvVar{
 			Name:  constants.EnvPodNamespace,
-			Value: eg.Namespace,
+			Value: eg.namespace,
 		},
 		corev1.EnvVar{
 			Name:  constants.EnvEgressName,
-			Value: eg.Name,
+			Value: eg.GetName(),
 		},
 		corev1.EnvVar{
 			Name: constants.EnvAddresses,
@@ -169,12 +241,48 @@ func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *
 			},
 		},
 	)
-	egressContainer.VolumeMounts = r.addVolumeMounts(egressContainer.VolumeMounts)
-	egressContainer.SecurityContext = &corev1.SecurityContext{
-		Privileged:             pointer.Bool(true),
-		ReadOnlyRootFilesystem: pointer.Bool(true),
-		Capabilities:           &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+	if eg.cluster {
+		egressContainer.Env = setEnv(egressContainer.Env, corev1.EnvVar{
+			Name:  constants.EnvClusterEgress,
+			Value: "true",
+		})
+	}
+	if eg.spec.Interface != "" {
+		egressContainer.Env = setEnv(egressContainer.Env, corev1.EnvVar{
+			Name:  constants.EnvInterface,
+			Value: eg.spec.Interface,
+		})
 	}
+	var hops []string
+	for _, d := range eg.spec.Destinations {
+		if d.NextHop != "" {
+			hops = append(hops, d.CIDR+"="+d.NextHop)
+		}
+	}
+	if len(hops) > 0 {
+		egressContainer.Env = setEnv(egressContainer.Env, corev1.EnvVar{
+			Name:  constants.EnvNextHops,
+			Value: strings.Join(hops, ","),
+		})
+	}
+	if eg.spec.NetworkAttachment != "" {
+		egressContainer.Env = setEnv(egressContainer.Env,
+			corev1.EnvVar{
+				Name:  constants.EnvNetwork,
+				Value: eg.spec.NetworkAttachment,
+			},
+			corev1.EnvVar{
+				Name: constants.EnvPodName,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+		)
+	}
+	egressContainer.VolumeMounts = r.addVolumeMounts(egressContainer.VolumeMounts)
+	mergeSecurityContext(egressContainer)
 	if egressContainer.Resources.Requests == nil {
 		egressContainer.Resources.Requests = make(corev1.ResourceList)
 	}
@@ -184,113 +292,244 @@ func (r *EgressReconciler) reconcilePodTemplate(eg *egressv1beta1.Egress, depl *
 	if _, ok := egressContainer.Resources.Requests[corev1.ResourceMemory]; !ok {
 		egressContainer.Resources.Requests[corev1.ResourceMemory] = resource.MustParse("200Mi")
 	}
-	egressContainer.Ports = []corev1.ContainerPort{
+	mergePorts(egressContainer)
+	mergeProbes(egressContainer)
+	podSpec.DeepCopyInto(&target.Spec)
+}
+
+// The security context, ports and probes of the egress-gw container are
+// merged with the template: fields left empty get the defaults, and fields
+// specified in the template are kept as they are.
+
+// mergeSecurityContext fills the unspecified fields of the security context.
+// Privileged is true by default.  To drop it, the template needs to set
+// privileged to false explicitly.
+func mergeSecurityContext(c *corev1.Container) {
+	if c.SecurityContext == nil {
+		c.SecurityContext = &corev1.SecurityContext{}
+	}
+	sc := c.SecurityContext
+	if sc.Privileged == nil {
+		sc.Privileged = pointer.Bool(true)
+	}
+	if sc.ReadOnlyRootFilesystem == nil {
+		sc.ReadOnlyRootFilesystem = pointer.Bool(true)
+	}
+	if sc.Capabilities == nil {
+		sc.Capabilities = &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}}
+	}
+}
+
+// mergePorts adds the metrics and health ports unless the template has
+// ports of the same names.
+func mergePorts(c *corev1.Container) {
+	defaults := []corev1.ContainerPort{
 		{Name: "metrics", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
 		{Name: "health", ContainerPort: 8081, Protocol: corev1.ProtocolTCP},
 	}
-	egressContainer.LivenessProbe = &corev1.Probe{
-		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+
+OUTER:
+	for _, d := range defaults {
+		for _, p := range c.Ports {
+			if p.Name == d.Name {
+				continue OUTER
+			}
+		}
+		c.Ports = append(c.Ports, d)
+	}
+}
+
+// mergeProbes sets the default probes.  If a probe in the template
+// has no handler, only the handler is filled so that timings can be tuned.
+func mergeProbes(c *corev1.Container) {
+	if c.LivenessProbe == nil {
+		c.LivenessProbe = &corev1.Probe{}
+	}
+	if isEmptyProbeHandler(c.LivenessProbe.ProbeHandler) {
+		c.LivenessProbe.ProbeHandler = corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
 			Path:   "/healthz",
 			Port:   intstr.FromString("health"),
 			Scheme: corev1.URISchemeHTTP,
-		}},
+		}}
 	}
-	egressContainer.ReadinessProbe = &corev1.Probe{
-		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+
+	if c.ReadinessProbe == nil {
+		c.ReadinessProbe = &corev1.Probe{}
+	}
+	if isEmptyProbeHandler(c.ReadinessProbe.ProbeHandler) {
+		c.ReadinessProbe.ProbeHandler = corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
 			Path:   "/readyz",
 			Port:   intstr.FromString("health"),
 			Scheme: corev1.URISchemeHTTP,
-		}},
+		}}
 	}
+}
 
-	podSpec.DeepCopyInto(&target.Spec)
+func isEmptyProbeHandler(h corev1.ProbeHandler) bool {
+	return h.Exec == nil && h.HTTPGet == nil && h.TCPSocket == nil && h.GRPC == nil
 }
 
-func (r *EgressReconciler) addVolumes(vols []corev1.Volume) []corev1.Volume {
-	noRun := true
-	for _, vol := range vols {
-		if vol.Name == "run" {
-			noRun = false
-			break
+// addTopologySpread spreads egress pods across nodes and zones unless
+// the pod template specifies its own placement.
+func (r *EgressReconciler) addTopologySpread(eg *egressObject, podSpec *corev1.PodSpec) {
+	ts := eg.spec.TopologySpread
+	if ts == nil {
+		ts = &egressv1.EgressTopologySpread{}
+	}
+	if ts.Disabled {
+		return
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: selectorLabels(eg.name)}
+	if len(podSpec.TopologySpreadConstraints) == 0 {
+		nodeAction := ts.NodeWhenUnsatisfiable
+		if nodeAction == "" {
+			nodeAction = corev1.ScheduleAnyway
+		}
+		zoneAction := ts.ZoneWhenUnsatisfiable
+		if zoneAction == "" {
+			zoneAction = corev1.ScheduleAnyway
+		}
+		podSpec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelHostname,
+				WhenUnsatisfiable: nodeAction,
+				LabelSelector:     selector,
+			},
+			{
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: zoneAction,
+				LabelSelector:     selector,
+			},
+		}
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: selector,
+					TopologyKey:   corev1.LabelHostname,
+				},
+			}},
 		}
 	}
-	if noRun {
-		vols = append(vols, corev1.Volume{
+}
+
+// setEnv sets environment variables for egress-gw.
+// Variables of the same names are replaced so that the result does not
+// depend on what the template has.
+func setEnv(envs []corev1.EnvVar, vars ...corev1.EnvVar) []corev1.EnvVar {
+OUTER:
+	for _, v := range vars {
+		for i := range envs {
+			if envs[i].Name == v.Name {
+				envs[i] = v
+				continue OUTER
+			}
+		}
+		envs = append(envs, v)
+	}
+	return envs
+}
+
+func (r *EgressReconciler) addVolumes(vols []corev1.Volume) []corev1.Volume {
+	defaults := []corev1.Volume{
+		{
 			Name: "run",
 			VolumeSource: corev1.VolumeSource{
 				EmptyDir: &corev1.EmptyDirVolumeSource{},
 			},
-		})
-	}
-
-	vols = append(vols, corev1.Volume{
-		Name: "modules",
-		VolumeSource: corev1.VolumeSource{
-			HostPath: &corev1.HostPathVolumeSource{
-				Path: "/lib/modules",
+		},
+		{
+			Name: "modules",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/lib/modules",
+				},
 			},
 		},
-	})
+	}
+
+OUTER:
+	for _, d := range defaults {
+		for _, vol := range vols {
+			if vol.Name == d.Name {
+				continue OUTER
+			}
+		}
+		vols = append(vols, d)
+	}
 	return vols
 }
 
 func (r *EgressReconciler) addVolumeMounts(mounts []corev1.VolumeMount) []corev1.VolumeMount {
-	noRun := true
-	for _, m := range mounts {
-		if m.Name == "run" {
-			noRun = false
-			break
-		}
-	}
-	if noRun {
-		mounts = append(mounts, corev1.VolumeMount{
+	defaults := []corev1.VolumeMount{
+		{
 			MountPath: "/run",
 			Name:      "run",
 			ReadOnly:  false,
-		})
+		},
+		{
+			MountPath: "/lib/modules",
+			Name:      "modules",
+			ReadOnly:  true,
+		},
 	}
 
-	mounts = append(mounts, corev1.VolumeMount{
-		MountPath: "/lib/modules",
-		Name:      "modules",
-		ReadOnly:  true,
-	})
-
+OUTER:
+	for _, d := range defaults {
+		for _, m := range mounts {
+			if m.Name == d.Name {
+				continue OUTER
+			}
+		}
+		mounts = append(mounts, d)
+	}
 	return mounts
 }
 
-func (r *EgressReconciler) reconcileDeployment(ctx context.Context, log logr.Logger, eg *egressv1beta1.Egress) error {
+func (r *EgressReconciler) reconcileDeployment(ctx context.Context, log logr.Logger, eg *egressObject) error {
 	depl := &appsv1.Deployment{}
-	depl.Namespace = eg.Namespace
-	depl.Name = eg.Name
+	depl.Namespace = eg.namespace
+	depl.Name = eg.name
 	result, err := ctrl.CreateOrUpdate(ctx, r.Client, depl, func() error {
 		if depl.DeletionTimestamp != nil {
 			return nil
 		}
+		if !depl.CreationTimestamp.IsZero() && !metav1.IsControlledBy(depl, eg) {
+			return fmt.Errorf("deployment %s/%s is not controlled by %s", depl.Namespace, depl.Name, eg.GetName())
+		}
 
 		if depl.Labels == nil {
 			depl.Labels = make(map[string]string)
 		}
-		labels := selectorLabels(eg.Name)
+		labels := selectorLabels(eg.name)
 		for k, v := range labels {
 			depl.Labels[k] = v
 		}
 
 		// set immutable fields only for a new object
 		if depl.CreationTimestamp.IsZero() {
-			if err := ctrl.SetControllerReference(eg, depl, r.Scheme); err != nil {
+			if err := ctrl.SetControllerReference(eg.Object, depl, r.Scheme); err != nil {
 				return err
 			}
 			depl.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
 		}
 
-		if depl.Spec.Replicas == nil || *depl.Spec.Replicas != eg.Spec.Replicas {
-			replicas := eg.Spec.Replicas
+		if depl.Spec.Replicas == nil || *depl.Spec.Replicas != eg.spec.Replicas {
+			replicas := eg.spec.Replicas
 			depl.Spec.Replicas = &replicas
 		}
 
-		if eg.Spec.Strategy != nil {
-			eg.Spec.Strategy.DeepCopyInto(&depl.Spec.Strategy)
+		if eg.spec.Strategy != nil {
+			eg.spec.Strategy.DeepCopyInto(&depl.Spec.Strategy)
 		}
 		r.reconcilePodTemplate(eg, depl)
 
@@ -306,26 +545,29 @@ func (r *EgressReconciler) reconcileDeployment(ctx context.Context, log logr.Log
 	return nil
 }
 
-func (r *EgressReconciler) reconcileService(ctx context.Context, log logr.Logger, eg *egressv1beta1.Egress) error {
+func (r *EgressReconciler) reconcileService(ctx context.Context, log logr.Logger, eg *egressObject) error {
 	svc := &corev1.Service{}
-	svc.Namespace = eg.Namespace
-	svc.Name = eg.Name
+	svc.Namespace = eg.namespace
+	svc.Name = eg.name
 	result, err := ctrl.CreateOrUpdate(ctx, r.Client, svc, func() error {
 		if svc.DeletionTimestamp != nil {
 			return nil
 		}
+		if !svc.CreationTimestamp.IsZero() && !metav1.IsControlledBy(svc, eg) {
+			return fmt.Errorf("service %s/%s is not controlled by %s", svc.Namespace, svc.Name, eg.GetName())
+		}
 
 		if svc.Labels == nil {
 			svc.Labels = make(map[string]string)
 		}
-		labels := selectorLabels(eg.Name)
+		labels := selectorLabels(eg.name)
 		for k, v := range labels {
 			svc.Labels[k] = v
 		}
 
 		// set immutable fields only for a new object
 		if svc.CreationTimestamp.IsZero() {
-			if err := ctrl.SetControllerReference(eg, svc, r.Scheme); err != nil {
+			if err := ctrl.SetControllerReference(eg.Object, svc, r.Scheme); err != nil {
 				return err
 			}
 		}
@@ -337,10 +579,10 @@ func (r *EgressReconciler) reconcileService(ctx context.Context, log logr.Logger
 			TargetPort: intstr.FromInt(int(r.Port)),
 			Protocol:   corev1.ProtocolUDP,
 		}}
-		svc.Spec.SessionAffinity = eg.Spec.SessionAffinity
-		if eg.Spec.SessionAffinityConfig != nil {
+		svc.Spec.SessionAffinity = eg.spec.SessionAffinity
+		if eg.spec.SessionAffinityConfig != nil {
 			sac := &corev1.SessionAffinityConfig{}
-			eg.Spec.SessionAffinityConfig.DeepCopyInto(sac)
+			eg.spec.SessionAffinityConfig.DeepCopyInto(sac)
 			svc.Spec.SessionAffinityConfig = sac
 		}
 
@@ -356,9 +598,64 @@ func (r *EgressReconciler) reconcileService(ctx context.Context, log logr.Logger
 	return nil
 }
 
-func (r *EgressReconciler) updateStatus(ctx context.Context, log logr.Logger, eg *egressv1beta1.Egress) error {
+func (r *EgressReconciler) reconcilePDB(ctx context.Context, log logr.Logger, eg *egressObject) error {
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdb.Namespace = eg.namespace
+	pdb.Name = eg.name
+	result, err := ctrl.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		if pdb.DeletionTimestamp != nil {
+			return nil
+		}
+		if !pdb.CreationTimestamp.IsZero() && !metav1.IsControlledBy(pdb, eg) {
+			return fmt.Errorf("pod disruption budget %s/%s is not controlled by %s", pdb.Namespace, pdb.Name, eg.GetName())
+		}
+
+		if pdb.Labels == nil {
+			pdb.Labels = make(map[string]string)
+		}
+		labels := selectorLabels(eg.name)
+		for k, v := range labels {
+			pdb.Labels[k] = v
+		}
+
+		// set immutable fields only for a new object
+		if pdb.CreationTimestamp.IsZero() {
+			if err := ctrl.SetControllerReference(eg.Object, pdb, r.Scheme); err != nil {
+				return err
+			}
+		}
+
+		pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = nil
+		spec := eg.spec.PodDisruptionBudget
+		switch {
+		case spec != nil && spec.MinAvailable != nil:
+			v := *spec.MinAvailable
+			pdb.Spec.MinAvailable = &v
+		case spec != nil && spec.MaxUnavailable != nil:
+			v := *spec.MaxUnavailable
+			pdb.Spec.MaxUnavailable = &v
+		default:
+			v := intstr.FromInt(1)
+			pdb.Spec.MaxUnavailable = &v
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if result != controllerutil.OperationResultNone {
+		log.Info(string(result) + " pod disruption budget")
+	}
+	return nil
+}
+
+func (r *EgressReconciler) updateStatus(ctx context.Context, log logr.Logger, eg *egressObject) error {
 	depl := &appsv1.Deployment{}
-	if err := r.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl); err != nil {
+	if err := r.Get(ctx, client.ObjectKey{Namespace: eg.namespace, Name: eg.name}, depl); err != nil {
 		return err
 	}
 
@@ -369,17 +666,45 @@ func (r *EgressReconciler) updateStatus(ctx context.Context, log logr.Logger, eg
 	selString := sel.String()
 
 	changed := false
-	if eg.Status.Selector != selString {
+	if eg.status.Selector != selString {
+		changed = true
+		eg.status.Selector = selString
+	}
+	if eg.status.Replicas != depl.Status.AvailableReplicas {
+		changed = true
+		eg.status.Replicas = depl.Status.AvailableReplicas
+	}
+	if eg.status.ReadyReplicas != depl.Status.ReadyReplicas {
+		changed = true
+		eg.status.ReadyReplicas = depl.Status.ReadyReplicas
+	}
+	if eg.status.ObservedGeneration != eg.GetGeneration() {
 		changed = true
-		eg.Status.Selector = selString
+		eg.status.ObservedGeneration = eg.GetGeneration()
 	}
-	if eg.Status.Replicas != depl.Status.AvailableReplicas {
+
+	cond := metav1.Condition{
+		Type:               egressv1.EgressAvailable,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: eg.GetGeneration(),
+		Reason:             "MinimumReplicasUnavailable",
+		Message:            "Deployment does not have minimum availability.",
+	}
+	for _, c := range depl.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+			cond.Status = metav1.ConditionTrue
+			cond.Reason = "MinimumReplicasAvailable"
+			cond.Message = "Deployment has minimum availability."
+		}
+	}
+	if cur := meta.FindStatusCondition(eg.status.Conditions, cond.Type); cur == nil ||
+		cur.Status != cond.Status || cur.Reason != cond.Reason || cur.ObservedGeneration != cond.ObservedGeneration {
 		changed = true
-		eg.Status.Replicas = depl.Status.AvailableReplicas
+		meta.SetStatusCondition(&eg.status.Conditions, cond)
 	}
 
 	if changed {
-		if err := r.Status().Update(ctx, eg); err != nil {
+		if err := r.Status().Update(ctx, eg.Object); err != nil {
 			return err
 		}
 		log.Info("updated status")
@@ -390,11 +715,29 @@ func (r *EgressReconciler) updateStatus(ctx context.Context, log logr.Logger, eg
 
 // SetupWithManager registers this with the manager.
 func (r *EgressReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&egressv1beta1.Egress{}).
+	if r.Metrics != nil {
+		r.autoscaler = newAutoscaler(r.Metrics)
+	}
+	mode, err := resolveNetworkPolicyMode(r.NetworkPolicy, mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	r.networkPolicy = mode
+
+	ownedByCE := handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &egressv1.ClusterEgress{}, handler.OnlyControllerOwner())
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&egressv1.Egress{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
-		Complete(r)
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(&egressv1.ClusterEgress{}, &handler.EnqueueRequestForObject{}).
+		Watches(&appsv1.Deployment{}, ownedByCE).
+		Watches(&corev1.Service{}, ownedByCE).
+		Watches(&policyv1.PodDisruptionBudget{}, ownedByCE)
+	if np := networkPolicyObject(mode); np != nil {
+		b = b.Owns(np).Watches(networkPolicyObject(mode), ownedByCE)
+	}
+	return b.Complete(r)
 }
 
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get
@@ -7,22 +7,28 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	. "github.com/onsi/gomega/gstruct"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func makeEgress(name string) *egressv1beta1.Egress {
-	eg := &egressv1beta1.Egress{}
+func makeEgress(name string) *egressv1.Egress {
+	eg := &egressv1.Egress{}
 	eg.Namespace = "default"
 	eg.Name = name
-	eg.Spec.Destinations = []string{"10.1.2.0/24", "fd03::/120"}
+	eg.Spec.Destinations = []egressv1.EgressDestination{{CIDR: "10.1.2.0/24"}, {CIDR: "fd03::/120"}}
 	eg.Spec.Replicas = 1
 	eg.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
 	return eg
@@ -45,6 +51,8 @@ var _ = Describe("Egress reconciler", func() {
 			Scheme: mgr.GetScheme(),
 			Image:  "egress-gw:dev",
 			Port:   5555,
+
+			NetworkPolicy: NetworkPolicyAuto,
 		}
 		err = egr.SetupWithManager(mgr)
 		Expect(err).ToNot(HaveOccurred())
@@ -137,7 +145,7 @@ var _ = Describe("Egress reconciler", func() {
 
 		By("checking status")
 		Eventually(func() error {
-			eg := &egressv1beta1.Egress{}
+			eg := &egressv1.Egress{}
 			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg1"}, eg)
 			if err != nil {
 				return err
@@ -146,6 +154,12 @@ var _ = Describe("Egress reconciler", func() {
 			if eg.Status.Selector == "" {
 				return errors.New("status is not updated")
 			}
+			if eg.Status.ObservedGeneration != eg.Generation {
+				return errors.New("observedGeneration is not updated")
+			}
+			if !meta.IsStatusConditionFalse(eg.Status.Conditions, egressv1.EgressAvailable) {
+				return errors.New("available condition is not updated")
+			}
 
 			return nil
 		}).Should(Succeed())
@@ -171,7 +185,7 @@ var _ = Describe("Egress reconciler", func() {
 
 		By("changing Egress")
 		Eventually(func() error {
-			eg = &egressv1beta1.Egress{}
+			eg = &egressv1.Egress{}
 			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg2"}, eg)
 			if err != nil {
 				return err
@@ -183,8 +197,8 @@ var _ = Describe("Egress reconciler", func() {
 				MaxSurge:       &maxSurge,
 				MaxUnavailable: &maxUnavailable,
 			}
-			eg.Spec.Template = &egressv1beta1.EgressPodTemplate{
-				Metadata: egressv1beta1.Metadata{
+			eg.Spec.Template = &egressv1.EgressPodTemplate{
+				Metadata: egressv1.Metadata{
 					Annotations: map[string]string{
 						"ann1": "qqq",
 					},
@@ -246,13 +260,13 @@ var _ = Describe("Egress reconciler", func() {
 
 		By("changing Egress to customize egress container")
 		Eventually(func() error {
-			eg = &egressv1beta1.Egress{}
+			eg = &egressv1.Egress{}
 			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg2"}, eg)
 			if err != nil {
 				return err
 			}
-			eg.Spec.Template = &egressv1beta1.EgressPodTemplate{
-				Metadata: egressv1beta1.Metadata{
+			eg.Spec.Template = &egressv1.EgressPodTemplate{
+				Metadata: egressv1.Metadata{
 					Annotations: nil,
 					Labels:      nil,
 				},
@@ -306,6 +320,147 @@ var _ = Describe("Egress reconciler", func() {
 		Expect(egressContainer.ReadinessProbe).NotTo(BeNil())
 	})
 
+	It("should keep security context, ports and probes in the template", func() {
+		By("creating an Egress with an unprivileged egress container")
+		eg := makeEgress("eg-merge")
+		eg.Spec.Template = &egressv1.EgressPodTemplate{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "egress-gw",
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: pointer.Bool(false),
+						Capabilities: &corev1.Capabilities{
+							Add:  []corev1.Capability{"NET_ADMIN"},
+							Drop: []corev1.Capability{"ALL"},
+						},
+					},
+					Ports: []corev1.ContainerPort{
+						{Name: "metrics", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+					},
+					LivenessProbe: &corev1.Probe{PeriodSeconds: 30, TimeoutSeconds: 5},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("health")}},
+					},
+				}},
+			},
+		}
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking the egress container")
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		Expect(depl.Spec.Template.Spec.Containers).To(HaveLen(1))
+		c := &depl.Spec.Template.Spec.Containers[0]
+		Expect(c.SecurityContext.Privileged).To(PointTo(BeFalse()))
+		Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(PointTo(BeTrue()))
+		Expect(c.SecurityContext.Capabilities.Add).To(Equal([]corev1.Capability{"NET_ADMIN"}))
+		Expect(c.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+		Expect(c.Ports).To(ConsistOf(
+			corev1.ContainerPort{Name: "metrics", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+			corev1.ContainerPort{Name: "health", ContainerPort: 8081, Protocol: corev1.ProtocolTCP},
+		))
+		Expect(c.LivenessProbe.PeriodSeconds).To(Equal(int32(30)))
+		Expect(c.LivenessProbe.TimeoutSeconds).To(Equal(int32(5)))
+		Expect(c.LivenessProbe.HTTPGet).NotTo(BeNil())
+		Expect(c.LivenessProbe.HTTPGet.Path).To(Equal("/healthz"))
+		Expect(c.ReadinessProbe.HTTPGet).To(BeNil())
+		Expect(c.ReadinessProbe.TCPSocket).NotTo(BeNil())
+	})
+
+	It("should pass the interface name to egress pods", func() {
+		By("creating an Egress with interface")
+		eg := makeEgress("eg-iface")
+		eg.Spec.Interface = "net1"
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking Deployment")
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		var egressContainer *corev1.Container
+		for i := range depl.Spec.Template.Spec.Containers {
+			c := &depl.Spec.Template.Spec.Containers[i]
+			if c.Name == "egress-gw" {
+				egressContainer = c
+				break
+			}
+		}
+		Expect(egressContainer).NotTo(BeNil())
+		Expect(egressContainer.Env).To(HaveLen(4))
+		Expect(egressContainer.Env).To(ContainElement(corev1.EnvVar{Name: constants.EnvInterface, Value: "net1"}))
+	})
+
+	It("should pass next hops to egress pods", func() {
+		By("creating an Egress with next hops")
+		eg := makeEgress("eg-nexthop")
+		eg.Spec.Destinations = append(eg.Spec.Destinations,
+			egressv1.EgressDestination{CIDR: "10.1.2.0/25", NextHop: "192.0.2.1"},
+			egressv1.EgressDestination{CIDR: "10.1.2.128/25", NextHop: "192.0.2.2"},
+		)
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking Deployment")
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		var egressContainer *corev1.Container
+		for i := range depl.Spec.Template.Spec.Containers {
+			c := &depl.Spec.Template.Spec.Containers[i]
+			if c.Name == "egress-gw" {
+				egressContainer = c
+				break
+			}
+		}
+		Expect(egressContainer).NotTo(BeNil())
+		Expect(egressContainer.Env).To(ContainElement(corev1.EnvVar{
+			Name:  constants.EnvNextHops,
+			Value: "10.1.2.0/25=192.0.2.1,10.1.2.128/25=192.0.2.2",
+		}))
+	})
+
+	It("should attach the network to egress pods", func() {
+		By("creating an Egress with network attachment")
+		eg := makeEgress("eg-multus")
+		eg.Spec.NetworkAttachment = "uplink"
+		eg.Spec.Interface = "net1"
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking Deployment")
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		Expect(depl.Spec.Template.Annotations).To(HaveKeyWithValue("k8s.v1.cni.cncf.io/networks", "uplink@net1"))
+
+		var egressContainer *corev1.Container
+		for i := range depl.Spec.Template.Spec.Containers {
+			c := &depl.Spec.Template.Spec.Containers[i]
+			if c.Name == "egress-gw" {
+				egressContainer = c
+				break
+			}
+		}
+		Expect(egressContainer).NotTo(BeNil())
+		Expect(egressContainer.Env).To(HaveLen(6))
+		Expect(egressContainer.Env).To(ContainElement(corev1.EnvVar{Name: constants.EnvNetwork, Value: "uplink"}))
+	})
+
 	It("should allow customization of Service", func() {
 		By("creating an Egress")
 		var timeout int32 = 100
@@ -331,7 +486,7 @@ var _ = Describe("Egress reconciler", func() {
 
 		By("changing Egress to change SessionAffinity")
 		Eventually(func() error {
-			eg = &egressv1beta1.Egress{}
+			eg = &egressv1.Egress{}
 			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg3"}, eg)
 			if err != nil {
 				return err
@@ -444,6 +599,244 @@ var _ = Describe("Egress reconciler", func() {
 		Expect(saNS).To(HaveKey("egtest"))
 	})
 
+	It("should manage PodDisruptionBudget", func() {
+		By("creating an Egress")
+		eg := makeEgress("eg-pdb")
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking the default PodDisruptionBudget")
+		var pdb *policyv1.PodDisruptionBudget
+		Eventually(func() error {
+			pdb = &policyv1.PodDisruptionBudget{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, pdb)
+		}).Should(Succeed())
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+		Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue(constants.LabelAppInstance, eg.Name))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(pdb.Spec.MaxUnavailable).To(PointTo(Equal(intstr.FromInt(1))))
+
+		By("updating the Egress")
+		Eventually(func() error {
+			eg := &egressv1.Egress{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg-pdb"}, eg); err != nil {
+				return err
+			}
+			minAvailable := intstr.FromString("50%")
+			eg.Spec.PodDisruptionBudget = &egressv1.EgressPDBSpec{MinAvailable: &minAvailable}
+			return k8sClient.Update(ctx, eg)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			pdb = &policyv1.PodDisruptionBudget{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, pdb); err != nil {
+				return err
+			}
+			if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.String() != "50%" {
+				return errors.New("minAvailable is not updated")
+			}
+			if pdb.Spec.MaxUnavailable != nil {
+				return errors.New("maxUnavailable is not cleared")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should restrict traffic of egress pods with NetworkPolicy", func() {
+		By("creating an Egress")
+		eg := makeEgress("eg-netpol")
+		eg.Spec.AllowedNamespaces = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking the NetworkPolicy")
+		var np *networkingv1.NetworkPolicy
+		Eventually(func() error {
+			np = &networkingv1.NetworkPolicy{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, np)
+		}).Should(Succeed())
+		Expect(np.OwnerReferences).To(HaveLen(1))
+		Expect(np.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(constants.LabelAppInstance, eg.Name))
+		Expect(np.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+
+		udp := corev1.ProtocolUDP
+		fouPort := intstr.FromInt(5555)
+		clients := []networkingv1.NetworkPolicyPeer{
+			{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+			{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "default"}}},
+		}
+		Expect(np.Spec.Ingress).To(HaveLen(2))
+		Expect(np.Spec.Ingress[0].From).To(Equal(clients))
+		Expect(np.Spec.Ingress[0].Ports).To(Equal([]networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &fouPort}}))
+		Expect(np.Spec.Ingress[1].From).To(BeEmpty())
+
+		Expect(len(np.Spec.Egress)).To(BeNumerically(">=", 2))
+		Expect(np.Spec.Egress[0].To).To(Equal([]networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.1.2.0/24"}},
+			{IPBlock: &networkingv1.IPBlock{CIDR: "fd03::/120"}},
+		}))
+		Expect(np.Spec.Egress[0].Ports).To(BeEmpty())
+		Expect(np.Spec.Egress[1].To).To(Equal(clients))
+
+		By("updating the Egress")
+		Eventually(func() error {
+			eg := &egressv1.Egress{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "eg-netpol"}, eg); err != nil {
+				return err
+			}
+			eg.Spec.AllowedNamespaces = nil
+			return k8sClient.Update(ctx, eg)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			np = &networkingv1.NetworkPolicy{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, np); err != nil {
+				return err
+			}
+			if len(np.Spec.Ingress[0].From) != 1 {
+				return errors.New("ingress is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+		Expect(np.Spec.Ingress[0].From[0].NamespaceSelector).To(Equal(&metav1.LabelSelector{}))
+	})
+
+	It("should spread egress pods across nodes and zones", func() {
+		By("creating an Egress with the default placement")
+		eg := makeEgress("eg-spread1")
+		err := k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		podSpec := &depl.Spec.Template.Spec
+		Expect(podSpec.TopologySpreadConstraints).To(HaveLen(2))
+		Expect(podSpec.TopologySpreadConstraints[0].TopologyKey).To(Equal(corev1.LabelHostname))
+		Expect(podSpec.TopologySpreadConstraints[0].WhenUnsatisfiable).To(Equal(corev1.ScheduleAnyway))
+		Expect(podSpec.TopologySpreadConstraints[1].TopologyKey).To(Equal(corev1.LabelTopologyZone))
+		Expect(podSpec.Affinity).NotTo(BeNil())
+		Expect(podSpec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+
+		By("creating an Egress with customized placement")
+		eg = makeEgress("eg-spread2")
+		eg.Spec.TopologySpread = &egressv1.EgressTopologySpread{ZoneWhenUnsatisfiable: corev1.DoNotSchedule}
+		eg.Spec.Template = &egressv1.EgressPodTemplate{}
+		eg.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+		err = k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+
+		podSpec = &depl.Spec.Template.Spec
+		Expect(podSpec.TopologySpreadConstraints).To(HaveLen(2))
+		Expect(podSpec.TopologySpreadConstraints[1].WhenUnsatisfiable).To(Equal(corev1.DoNotSchedule))
+		Expect(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+
+		By("creating an Egress without the default placement")
+		eg = makeEgress("eg-spread3")
+		eg.Spec.TopologySpread = &egressv1.EgressTopologySpread{Disabled: true}
+		err = k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: eg.Namespace, Name: eg.Name}, depl)
+		}).Should(Succeed())
+		Expect(depl.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+		Expect(depl.Spec.Template.Spec.Affinity).To(BeNil())
+	})
+
+	It("should create resources for ClusterEgress in its namespace", func() {
+		By("creating a ClusterEgress")
+		ce := &egressv1.ClusterEgress{}
+		ce.Name = "ceg1"
+		ce.Spec.Namespace = "internet"
+		ce.Spec.Destinations = []egressv1.EgressDestination{{CIDR: "10.1.3.0/24"}}
+		ce.Spec.Replicas = 1
+		ce.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+		err := k8sClient.Create(ctx, ce)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("checking Deployment and Service")
+		var depl *appsv1.Deployment
+		Eventually(func() error {
+			depl = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "internet", Name: "cluster-ceg1"}, depl)
+		}).Should(Succeed())
+		Expect(depl.OwnerReferences).To(HaveLen(1))
+		Expect(depl.OwnerReferences[0].Kind).To(Equal("ClusterEgress"))
+		Expect(depl.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: constants.EnvPodNamespace, Value: "internet"},
+			corev1.EnvVar{Name: constants.EnvEgressName, Value: "ceg1"},
+			corev1.EnvVar{Name: constants.EnvClusterEgress, Value: "true"},
+		))
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "internet", Name: "cluster-ceg1"}, &corev1.Service{})
+		}).Should(Succeed())
+
+		By("checking egress ClusterRoleBinding")
+		Eventually(func() []string {
+			crb := &rbacv1.ClusterRoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: "egress"}, crb); err != nil {
+				return nil
+			}
+			var namespaces []string
+			for _, s := range crb.Subjects {
+				namespaces = append(namespaces, s.Namespace)
+			}
+			return namespaces
+		}).Should(ContainElement("internet"))
+
+		By("checking status")
+		Eventually(func() error {
+			ce := &egressv1.ClusterEgress{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: "ceg1"}, ce); err != nil {
+				return err
+			}
+			if ce.Status.Selector == "" {
+				return errors.New("status is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("creating an Egress with the same name in the namespace")
+		eg := makeEgress("ceg1")
+		eg.Namespace = "internet"
+		err = k8sClient.Create(ctx, eg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() error {
+			depl := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "internet", Name: "ceg1"}, depl); err != nil {
+				return err
+			}
+			if depl.OwnerReferences[0].Kind != "Egress" {
+				return errors.New("deployment is not owned by Egress")
+			}
+			return nil
+		}).Should(Succeed())
+
+		Consistently(func() error {
+			depl := &appsv1.Deployment{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "internet", Name: "cluster-ceg1"}, depl); err != nil {
+				return err
+			}
+			if depl.OwnerReferences[0].Kind != "ClusterEgress" {
+				return errors.New("deployment is taken over by Egress")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
 	It("shouldn't remove annotations when reconciling deployments", func() {
 		By("creating an Egress")
 		eg := makeEgress("eg6")
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NetworkPolicyMode is the kind of network policy generated for egress pods.
+type NetworkPolicyMode string
+
+// Network policy modes
+const (
+	// NetworkPolicyAuto selects NetworkPolicyCilium if CiliumNetworkPolicy is available,
+	// or NetworkPolicyKubernetes otherwise.
+	NetworkPolicyAuto = NetworkPolicyMode("auto")
+
+	// NetworkPolicyKubernetes generates NetworkPolicy.
+	NetworkPolicyKubernetes = NetworkPolicyMode("kubernetes")
+
+	// NetworkPolicyCilium generates CiliumNetworkPolicy.
+	NetworkPolicyCilium = NetworkPolicyMode("cilium")
+
+	// NetworkPolicyNone does not generate network policies.
+	NetworkPolicyNone = NetworkPolicyMode("none")
+)
+
+var ciliumNetworkPolicyGVK = schema.GroupVersionKind{
+	Group:   "cilium.io",
+	Version: "v2",
+	Kind:    "CiliumNetworkPolicy",
+}
+
+// resolveNetworkPolicyMode resolves NetworkPolicyAuto.
+func resolveNetworkPolicyMode(mode NetworkPolicyMode, mapper meta.RESTMapper) (NetworkPolicyMode, error) {
+	switch mode {
+	case "", NetworkPolicyNone:
+		return NetworkPolicyNone, nil
+	case NetworkPolicyKubernetes, NetworkPolicyCilium:
+		return mode, nil
+	case NetworkPolicyAuto:
+	default:
+		return "", fmt.Errorf("invalid network policy mode: %s", mode)
+	}
+
+	_, err := mapper.RESTMapping(ciliumNetworkPolicyGVK.GroupKind(), ciliumNetworkPolicyGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return NetworkPolicyKubernetes, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return NetworkPolicyCilium, nil
+}
+
+// apiServerEndpoints returns the addresses and ports of the Kubernetes API server.
+func (r *EgressReconciler) apiServerEndpoints(ctx context.Context) ([]string, []int32, error) {
+	ep := &corev1.Endpoints{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "kubernetes"}, ep); err != nil {
+		return nil, nil, err
+	}
+
+	var addrs []string
+	var ports []int32
+	for _, ss := range ep.Subsets {
+		for _, a := range ss.Addresses {
+			addrs = append(addrs, a.IP)
+		}
+		for _, p := range ss.Ports {
+			ports = append(ports, p.Port)
+		}
+	}
+	return addrs, ports, nil
+}
+
+// clientNamespaceSelectors returns namespace selectors for pods allowed to use the Egress.
+func clientNamespaceSelectors(eg *egressObject) []*metav1.LabelSelector {
+	if eg.spec.AllowedNamespaces == nil {
+		return []*metav1.LabelSelector{{}}
+	}
+	return []*metav1.LabelSelector{
+		eg.spec.AllowedNamespaces,
+		{MatchLabels: map[string]string{corev1.LabelMetadataName: eg.namespace}},
+	}
+}
+
+func hostCIDR(ip string) string {
+	if net.ParseIP(ip).To4() != nil {
+		return ip + "/32"
+	}
+	return ip + "/128"
+}
+
+func (r *EgressReconciler) reconcileNetworkPolicy(ctx context.Context, log logr.Logger, eg *egressObject) error {
+	switch r.networkPolicy {
+	case NetworkPolicyKubernetes:
+		return r.reconcileKubernetesNetworkPolicy(ctx, log, eg)
+	case NetworkPolicyCilium:
+		return r.reconcileCiliumNetworkPolicy(ctx, log, eg)
+	}
+	return nil
+}
+
+// reconcileKubernetesNetworkPolicy allows egress pods to receive FoU packets only from
+// the client pods and to send packets only to the destinations and the API server.
+func (r *EgressReconciler) reconcileKubernetesNetworkPolicy(ctx context.Context, log logr.Logger, eg *egressObject) error {
+	apiAddrs, apiPorts, err := r.apiServerEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	np := &networkingv1.NetworkPolicy{}
+	np.Namespace = eg.namespace
+	np.Name = eg.name
+	result, err := ctrl.CreateOrUpdate(ctx, r.Client, np, func() error {
+		if np.DeletionTimestamp != nil {
+			return nil
+		}
+		if !np.CreationTimestamp.IsZero() && !metav1.IsControlledBy(np, eg) {
+			return fmt.Errorf("network policy %s/%s is not controlled by %s", np.Namespace, np.Name, eg.GetName())
+		}
+
+		if np.Labels == nil {
+			np.Labels = make(map[string]string)
+		}
+		labels := selectorLabels(eg.name)
+		for k, v := range labels {
+			np.Labels[k] = v
+		}
+
+		// set immutable fields only for a new object
+		if np.CreationTimestamp.IsZero() {
+			if err := ctrl.SetControllerReference(eg.Object, np, r.Scheme); err != nil {
+				return err
+			}
+		}
+
+		udp := corev1.ProtocolUDP
+		tcp := corev1.ProtocolTCP
+		fouPort := intstr.FromInt(int(r.Port))
+		fouPorts := []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &fouPort}}
+		var clients []networkingv1.NetworkPolicyPeer
+		for _, sel := range clientNamespaceSelectors(eg) {
+			clients = append(clients, networkingv1.NetworkPolicyPeer{NamespaceSelector: sel.DeepCopy()})
+		}
+
+		metricsPort := intstr.FromString("metrics")
+		healthPort := intstr.FromString("health")
+		np.Spec.PodSelector = metav1.LabelSelector{MatchLabels: labels}
+		np.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+			{From: clients, Ports: fouPorts},
+			{Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &metricsPort},
+				{Protocol: &tcp, Port: &healthPort},
+			}},
+		}
+
+		var dsts []networkingv1.NetworkPolicyPeer
+		for _, d := range eg.spec.ClientDestinations() {
+			dsts = append(dsts, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: d}})
+		}
+		var apiServers []networkingv1.NetworkPolicyPeer
+		for _, a := range apiAddrs {
+			apiServers = append(apiServers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: hostCIDR(a)}})
+		}
+		var apiServerPorts []networkingv1.NetworkPolicyPort
+		for _, p := range apiPorts {
+			port := intstr.FromInt(int(p))
+			apiServerPorts = append(apiServerPorts, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+		}
+		np.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{
+			{To: dsts},
+			{To: clients, Ports: fouPorts},
+		}
+		// a rule without peers allows all destinations.
+		if len(apiServers) > 0 {
+			np.Spec.Egress = append(np.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: apiServers, Ports: apiServerPorts})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if result != controllerutil.OperationResultNone {
+		log.Info(string(result) + " network policy")
+	}
+	return nil
+}
+
+// ciliumNamespaceSelector converts a namespace selector to an endpoint selector of Cilium.
+func ciliumNamespaceSelector(sel *metav1.LabelSelector) map[string]interface{} {
+	const prefix = "io.cilium.k8s.namespace.labels."
+
+	matchLabels := make(map[string]interface{})
+	for k, v := range sel.MatchLabels {
+		matchLabels[prefix+k] = v
+	}
+	matchExpressions := []interface{}{
+		// an empty selector in CiliumNetworkPolicy selects only the same namespace.
+		map[string]interface{}{
+			"key":      "io.kubernetes.pod.namespace",
+			"operator": string(metav1.LabelSelectorOpExists),
+		},
+	}
+	for _, e := range sel.MatchExpressions {
+		expr := map[string]interface{}{
+			"key":      prefix + e.Key,
+			"operator": string(e.Operator),
+		}
+		if len(e.Values) > 0 {
+			values := make([]interface{}, len(e.Values))
+			for i, v := range e.Values {
+				values[i] = v
+			}
+			expr["values"] = values
+		}
+		matchExpressions = append(matchExpressions, expr)
+	}
+
+	result := map[string]interface{}{"matchExpressions": matchExpressions}
+	if len(matchLabels) > 0 {
+		result["matchLabels"] = matchLabels
+	}
+	return result
+}
+
+// reconcileCiliumNetworkPolicy does the same as reconcileKubernetesNetworkPolicy
+// with CiliumNetworkPolicy.
+func (r *EgressReconciler) reconcileCiliumNetworkPolicy(ctx context.Context, log logr.Logger, eg *egressObject) error {
+	cnp := &unstructured.Unstructured{}
+	cnp.SetGroupVersionKind(ciliumNetworkPolicyGVK)
+	cnp.SetNamespace(eg.namespace)
+	cnp.SetName(eg.name)
+	result, err := ctrl.CreateOrUpdate(ctx, r.Client, cnp, func() error {
+		if cnp.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		created := cnp.GetCreationTimestamp()
+		if !created.IsZero() && !metav1.IsControlledBy(cnp, eg) {
+			return fmt.Errorf("cilium network policy %s/%s is not controlled by %s", cnp.GetNamespace(), cnp.GetName(), eg.GetName())
+		}
+
+		labels := cnp.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range selectorLabels(eg.name) {
+			labels[k] = v
+		}
+		cnp.SetLabels(labels)
+
+		// set immutable fields only for a new object
+		if created.IsZero() {
+			if err := ctrl.SetControllerReference(eg.Object, cnp, r.Scheme); err != nil {
+				return err
+			}
+		}
+
+		var clients []interface{}
+		for _, sel := range clientNamespaceSelectors(eg) {
+			clients = append(clients, ciliumNamespaceSelector(sel))
+		}
+		fouPorts := []interface{}{
+			map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"port": strconv.Itoa(int(r.Port)), "protocol": "UDP"},
+				},
+			},
+		}
+		var cidrs []interface{}
+		for _, d := range eg.spec.ClientDestinations() {
+			cidrs = append(cidrs, map[string]interface{}{"cidr": d})
+		}
+
+		endpointSelector := make(map[string]interface{})
+		for k, v := range selectorLabels(eg.name) {
+			endpointSelector[k] = v
+		}
+		spec := map[string]interface{}{
+			"endpointSelector": map[string]interface{}{"matchLabels": endpointSelector},
+			"ingress": []interface{}{
+				map[string]interface{}{"fromEndpoints": clients, "toPorts": fouPorts},
+				map[string]interface{}{
+					"fromEntities": []interface{}{"all"},
+					"toPorts": []interface{}{
+						map[string]interface{}{
+							"ports": []interface{}{
+								map[string]interface{}{"port": "metrics", "protocol": "TCP"},
+								map[string]interface{}{"port": "health", "protocol": "TCP"},
+							},
+						},
+					},
+				},
+			},
+			"egress": []interface{}{
+				map[string]interface{}{"toCIDRSet": cidrs},
+				map[string]interface{}{"toEndpoints": clients, "toPorts": fouPorts},
+				map[string]interface{}{"toEntities": []interface{}{"kube-apiserver"}},
+			},
+		}
+		return unstructured.SetNestedField(cnp.Object, spec, "spec")
+	})
+	if err != nil {
+		return err
+	}
+
+	if result != controllerutil.OperationResultNone {
+		log.Info(string(result) + " cilium network policy")
+	}
+	return nil
+}
+
+// networkPolicyObject returns an empty object of the network policy to be watched.
+func networkPolicyObject(mode NetworkPolicyMode) client.Object {
+	switch mode {
+	case NetworkPolicyKubernetes:
+		return &networkingv1.NetworkPolicy{}
+	case NetworkPolicyCilium:
+		cnp := &unstructured.Unstructured{}
+		cnp.SetGroupVersionKind(ciliumNetworkPolicyGVK)
+		return cnp
+	}
+	return nil
+}
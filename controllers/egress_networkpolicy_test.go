@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveNetworkPolicyMode(t *testing.T) {
+	withoutCilium := meta.NewDefaultRESTMapper(nil)
+	withCilium := meta.NewDefaultRESTMapper(nil)
+	withCilium.Add(ciliumNetworkPolicyGVK, meta.RESTScopeNamespace)
+
+	testCases := []struct {
+		mode     NetworkPolicyMode
+		mapper   meta.RESTMapper
+		expected NetworkPolicyMode
+	}{
+		{"", withCilium, NetworkPolicyNone},
+		{NetworkPolicyNone, withCilium, NetworkPolicyNone},
+		{NetworkPolicyKubernetes, withCilium, NetworkPolicyKubernetes},
+		{NetworkPolicyCilium, withoutCilium, NetworkPolicyCilium},
+		{NetworkPolicyAuto, withCilium, NetworkPolicyCilium},
+		{NetworkPolicyAuto, withoutCilium, NetworkPolicyKubernetes},
+	}
+	for _, tc := range testCases {
+		actual, err := resolveNetworkPolicyMode(tc.mode, tc.mapper)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.mode, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: expected %s, actual %s", tc.mode, tc.expected, actual)
+		}
+	}
+
+	if _, err := resolveNetworkPolicyMode("calico", withCilium); err == nil {
+		t.Error("invalid mode should be rejected")
+	}
+}
+
+func TestCiliumNamespaceSelector(t *testing.T) {
+	sel := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "a"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev"}},
+		},
+	}
+	expected := map[string]interface{}{
+		"matchLabels": map[string]interface{}{
+			"io.cilium.k8s.namespace.labels.team": "a",
+		},
+		"matchExpressions": []interface{}{
+			map[string]interface{}{"key": "io.kubernetes.pod.namespace", "operator": "Exists"},
+			map[string]interface{}{"key": "io.cilium.k8s.namespace.labels.env", "operator": "In", "values": []interface{}{"dev"}},
+		},
+	}
+	if actual := ciliumNamespaceSelector(sel); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected selector: %v", actual)
+	}
+
+	expected = map[string]interface{}{
+		"matchExpressions": []interface{}{
+			map[string]interface{}{"key": "io.kubernetes.pod.namespace", "operator": "Exists"},
+		},
+	}
+	if actual := ciliumNamespaceSelector(&metav1.LabelSelector{}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected selector for all namespaces: %v", actual)
+	}
+}
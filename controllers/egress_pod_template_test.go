@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestReconcilePodTemplateGolden(t *testing.T) {
+	e := &egressv1.Egress{}
+	e.Namespace = "internet"
+	e.Name = "egress"
+	e.Spec.Destinations = []egressv1.EgressDestination{{CIDR: "0.0.0.0/0"}, {CIDR: "192.168.0.0/16", NextHop: "10.0.0.1"}}
+	e.Spec.Replicas = 2
+	e.Spec.Interface = "net1"
+	e.Spec.NetworkAttachment = "macvlan"
+	e.Spec.Template = &egressv1.EgressPodTemplate{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "sidecar", Image: "nginx"}},
+		},
+	}
+	e.Default()
+
+	if len(e.Spec.Template.Spec.Containers) != 2 || e.Spec.Template.Spec.Containers[0].Name != egressv1.EgressContainerName {
+		t.Fatalf("the egress container is not defaulted: %+v", e.Spec.Template.Spec.Containers)
+	}
+
+	// variables and mounts given by the template are replaced, not duplicated.
+	e.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "EGRESS_GW_NAME", Value: "wrong"}}
+	e.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "modules", MountPath: "/lib/modules", ReadOnly: true}}
+
+	r := &EgressReconciler{Image: "egress-gw:dev", Port: 5555}
+	eg := &egressObject{Object: e, namespace: e.Namespace, name: e.Name, spec: &e.Spec, status: &e.Status}
+	depl := &appsv1.Deployment{}
+	r.reconcilePodTemplate(eg, depl)
+
+	data, err := json.MarshalIndent(depl.Spec.Template, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	golden := filepath.Join("testdata", "egress_pod_template.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("pod template differs from %s; run go test with -update to see the diff:\n%s", golden, data)
+	}
+
+	for i := 0; i < 3; i++ {
+		before := depl.DeepCopy()
+		r.reconcilePodTemplate(eg, depl)
+		if !equality.Semantic.DeepEqual(before, depl) {
+			t.Fatalf("reconcile #%d changed the deployment", i+2)
+		}
+	}
+}
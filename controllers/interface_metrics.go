@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// SetupInterfaceMetrics registers the traffic metrics of the network interface
+// through which NAT'ed packets go out of this egress pod.
+func SetupInterfaceMetrics(ns, name, iface string) error {
+	return metrics.Registry.Register(&interfaceCollector{
+		iface: iface,
+		bytes: prometheus.NewDesc(
+			prometheus.BuildFQName(constants.MetricsNS, "egress", "interface_bytes_total"),
+			"the number of bytes received or transmitted by the egress interface",
+			[]string{"direction"},
+			prometheus.Labels{"namespace": ns, "egress": name},
+		),
+	})
+}
+
+// interfaceCollector reads the statistics of the interface on every scrape.
+type interfaceCollector struct {
+	iface string
+	bytes *prometheus.Desc
+}
+
+func (c *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytes
+}
+
+func (c *interfaceCollector) Collect(ch chan<- prometheus.Metric) {
+	link, err := netlink.LinkByName(c.iface)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.bytes, err)
+		return
+	}
+	stats := link.Attrs().Statistics
+	if stats == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(stats.RxBytes), "receive")
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(stats.TxBytes), "transmit")
+}
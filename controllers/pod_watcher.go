@@ -10,15 +10,22 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/founat"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
@@ -37,16 +44,19 @@ func init() {
 	metrics.Registry.MustRegister(clientPods)
 }
 
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods;namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses;clusteregresses,verbs=get;list;watch
 
 // SetupPodWatcher registers pod watching reconciler to mgr.
-func SetupPodWatcher(mgr ctrl.Manager, ns, name string, ft founat.FoUTunnel, eg founat.Egress) error {
+// If `cluster` is true, `name` is the name of ClusterEgress and `ns` is its namespace for resources.
+func SetupPodWatcher(mgr ctrl.Manager, ns, name string, cluster bool, ft founat.FoUTunnel, eg founat.Egress) error {
 	clientPods.Reset()
 
 	r := &podWatcher{
 		client:   mgr.GetClient(),
 		myNS:     ns,
 		myName:   name,
+		cluster:  cluster,
 		ft:       ft,
 		eg:       eg,
 		metric:   clientPods.WithLabelValues(ns, name),
@@ -54,8 +64,15 @@ func SetupPodWatcher(mgr ctrl.Manager, ns, name string, ft founat.FoUTunnel, eg
 		peers:    make(map[string]map[string]struct{}),
 	}
 
+	var egObj client.Object = &egressv1.Egress{}
+	if cluster {
+		egObj = &egressv1.ClusterEgress{}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Pod{}).
+		For(&corev1.Pod{}, builder.WithPredicates(r.clientPodPredicate())).
+		Watches(egObj, handler.EnqueueRequestsFromMapFunc(r.mapEgress)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespace)).
 		Complete(r)
 }
 
@@ -65,31 +82,164 @@ func SetupPodWatcher(mgr ctrl.Manager, ns, name string, ft founat.FoUTunnel, eg
 // this implementation can leave some tunnels as garbage.  Such garbage tunnels
 // do no harm, though.
 type podWatcher struct {
-	client client.Client
-	myNS   string
-	myName string
-	ft     founat.FoUTunnel
-	eg     founat.Egress
-	metric prometheus.Gauge
+	client  client.Client
+	myNS    string
+	myName  string
+	cluster bool
+	ft      founat.FoUTunnel
+	eg      founat.Egress
+	metric  prometheus.Gauge
 
 	mu       sync.Mutex
 	podAddrs map[string][]net.IP
 	peers    map[string]map[string]struct{}
 }
 
-func (r *podWatcher) shouldHandle(pod *corev1.Pod) bool {
+// clientPodPredicate drops events of pods that do not use this egress.
+// Updates are passed if either the old or the new pod uses this egress
+// so that the tunnel is removed when the annotation is removed.
+func (r *podWatcher) clientPodPredicate() predicate.Predicate {
+	usesMe := func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return ok && r.usesMe(pod)
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return usesMe(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return usesMe(e.ObjectOld) || usesMe(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return usesMe(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return usesMe(e.Object)
+		},
+	}
+}
+
+// TrimPod is a cache transform function for egress pods.  It removes
+// fields of Pod that are not used by the pod watcher to reduce memory usage.
+func TrimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+
+	trimmed := &corev1.Pod{
+		TypeMeta: pod.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			DeletionTimestamp: pod.DeletionTimestamp,
+			Labels:            pod.Labels,
+			Annotations:       pod.Annotations,
+		},
+		Spec: corev1.PodSpec{
+			HostNetwork: pod.Spec.HostNetwork,
+		},
+		Status: corev1.PodStatus{
+			Phase:  pod.Status.Phase,
+			PodIP:  pod.Status.PodIP,
+			PodIPs: pod.Status.PodIPs,
+		},
+	}
+	return trimmed, nil
+}
+
+// mapEgress enqueues client pods when the Egress or ClusterEgress for this
+// egress pod is changed because its allowedNamespaces may be changed.
+func (r *podWatcher) mapEgress(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != r.egressKey().Namespace || obj.GetName() != r.myName {
+		return nil
+	}
+	return r.clientPodRequests(ctx)
+}
+
+// mapNamespace enqueues client pods in the namespace when its labels may be changed.
+func (r *podWatcher) mapNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetName() == r.myNS {
+		return nil
+	}
+	return r.clientPodRequests(ctx, client.InNamespace(obj.GetName()))
+}
+
+func (r *podWatcher) clientPodRequests(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list pods")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !r.usesMe(pod) {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}})
+	}
+	return reqs
+}
+
+func (r *podWatcher) shouldHandle(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if !r.usesMe(pod) {
+		return false, nil
+	}
+	if pod.Namespace == r.myNS {
+		return true, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: pod.Namespace}, ns); err != nil {
+		return false, err
+	}
+
+	if r.cluster {
+		ce := &egressv1.ClusterEgress{}
+		if err := r.client.Get(ctx, r.egressKey(), ce); err != nil {
+			return false, err
+		}
+		return ce.IsNamespaceAllowed(ns)
+	}
+
+	eg := &egressv1.Egress{}
+	if err := r.client.Get(ctx, r.egressKey(), eg); err != nil {
+		return false, err
+	}
+	return eg.IsNamespaceAllowed(ns)
+}
+
+// egressKey returns the key of Egress or ClusterEgress for this egress pod.
+func (r *podWatcher) egressKey() client.ObjectKey {
+	if r.cluster {
+		return client.ObjectKey{Name: r.myName}
+	}
+	return client.ObjectKey{Namespace: r.myNS, Name: r.myName}
+}
+
+func (r *podWatcher) usesMe(pod *corev1.Pod) bool {
 	if pod.Spec.HostNetwork {
 		// Egress feature is not available for Pods running in the host network.
 		return false
 	}
 
 	for k, v := range pod.Annotations {
-		if !strings.HasPrefix(k, constants.AnnEgressPrefix) {
-			continue
-		}
+		if r.cluster {
+			if k != constants.AnnClusterEgress {
+				continue
+			}
+		} else {
+			if !strings.HasPrefix(k, constants.AnnEgressPrefix) {
+				continue
+			}
 
-		if k[len(constants.AnnEgressPrefix):] != r.myNS {
-			continue
+			if k[len(constants.AnnEgressPrefix):] != r.myNS {
+				continue
+			}
 		}
 
 		// shortcut for the most typical case
@@ -116,9 +266,19 @@ func (r *podWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	pod := &corev1.Pod{}
 	err := r.client.Get(ctx, req.NamespacedName, pod)
 	if err == nil {
-		if !r.shouldHandle(pod) {
+		ok, err := r.shouldHandle(ctx, pod)
+		if err != nil {
+			logger.Error(err, "failed to check the pod")
 			return ctrl.Result{}, err
 		}
+		if !ok {
+			// the pod may have been allowed to use this egress before.
+			if err := r.delPod(req.NamespacedName, logger); err != nil {
+				logger.Error(err, "failed to remove tunnel")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
 
 		if !isTerminated(pod) {
 			if err := r.addPod(pod, logger); err != nil {
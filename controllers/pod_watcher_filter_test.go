@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestClientPodPredicate(t *testing.T) {
+	r := &podWatcher{myNS: "internet", myName: "egress"}
+	p := r.clientPodPredicate()
+
+	client := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "client",
+			Annotations: map[string]string{"egress.ysksuzuki.com/internet": "other,egress"},
+		},
+	}
+	other := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "other",
+			Annotations: map[string]string{"egress.ysksuzuki.com/internet": "other"},
+		},
+	}
+	hostNetwork := client.DeepCopy()
+	hostNetwork.Spec.HostNetwork = true
+
+	if !p.Create(event.CreateEvent{Object: client}) {
+		t.Error("creation of a client pod should be handled")
+	}
+	if p.Create(event.CreateEvent{Object: other}) {
+		t.Error("creation of a pod using another egress should be ignored")
+	}
+	if p.Create(event.CreateEvent{Object: hostNetwork}) {
+		t.Error("creation of a host network pod should be ignored")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: client, ObjectNew: client}) {
+		t.Error("update of a client pod should be handled")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: client, ObjectNew: other}) {
+		t.Error("update removing the annotation should be handled")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other}) {
+		t.Error("update of a pod using another egress should be ignored")
+	}
+	if !p.Delete(event.DeleteEvent{Object: client}) {
+		t.Error("deletion of a client pod should be handled")
+	}
+	if p.Delete(event.DeleteEvent{Object: other}) {
+		t.Error("deletion of a pod using another egress should be ignored")
+	}
+
+	cr := &podWatcher{myNS: "internet", myName: "platform", cluster: true}
+	cp := cr.clientPodPredicate()
+	ce := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "cluster-client",
+			Annotations: map[string]string{"egress.ysksuzuki.com": "platform"},
+		},
+	}
+	if !cp.Create(event.CreateEvent{Object: ce}) {
+		t.Error("creation of a ClusterEgress client pod should be handled")
+	}
+	if cp.Create(event.CreateEvent{Object: client}) {
+		t.Error("creation of an Egress client pod should be ignored by ClusterEgress")
+	}
+}
+
+func TestTrimPod(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "client",
+			ResourceVersion: "10",
+			Annotations:     map[string]string{"egress.ysksuzuki.com/internet": "egress"},
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   "node1",
+			Containers: []corev1.Container{{Name: "c1", Image: "nginx"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.1.0.1",
+			PodIPs:     []corev1.PodIP{{IP: "10.1.0.1"}, {IP: "fd01::1"}},
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	obj, err := TrimPod(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trimmed := obj.(*corev1.Pod)
+	if trimmed.Name != "client" || trimmed.ResourceVersion != "10" || trimmed.Annotations["egress.ysksuzuki.com/internet"] != "egress" {
+		t.Errorf("metadata should be kept: %+v", trimmed.ObjectMeta)
+	}
+	if trimmed.Status.Phase != corev1.PodRunning || len(trimmed.Status.PodIPs) != 2 {
+		t.Errorf("phase and pod IPs should be kept: %+v", trimmed.Status)
+	}
+	if trimmed.ManagedFields != nil || trimmed.Spec.Containers != nil || trimmed.Status.Conditions != nil {
+		t.Errorf("unused fields should be removed: %+v", trimmed)
+	}
+
+	if obj, err := TrimPod("foo"); err != nil || obj != "foo" {
+		t.Error("non-pod objects should be returned as is")
+	}
+}
@@ -10,7 +10,9 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -81,6 +83,14 @@ var _ = Describe("Pod watcher", func() {
 	var eg *mockEgress
 
 	BeforeEach(func() {
+		egress := &egressv1.Egress{}
+		egress.Namespace = "internet"
+		egress.Name = "egress2"
+		egress.Spec.Destinations = []egressv1.EgressDestination{{CIDR: "0.0.0.0/0"}, {CIDR: "::/0"}}
+		egress.Spec.Replicas = 1
+		err := k8sClient.Create(context.Background(), egress)
+		Expect(err).ShouldNot(HaveOccurred())
+
 		makePod("pod1", []string{"10.1.1.1", "fd01::1"}, nil)
 		makePod("pod2", []string{"10.1.1.2", "fd01::2"}, map[string]string{
 			"internet": "egress2",
@@ -103,7 +113,7 @@ var _ = Describe("Pod watcher", func() {
 		})
 		Expect(err).ToNot(HaveOccurred())
 
-		err = SetupPodWatcher(mgr, "internet", "egress2", ft, eg)
+		err = SetupPodWatcher(mgr, "internet", "egress2", false, ft, eg)
 		Expect(err).ToNot(HaveOccurred())
 
 		go func() {
@@ -119,6 +129,14 @@ var _ = Describe("Pod watcher", func() {
 		cancel()
 		err := k8sClient.DeleteAllOf(context.Background(), &corev1.Pod{}, client.InNamespace("default"))
 		Expect(err).ShouldNot(HaveOccurred())
+		err = k8sClient.DeleteAllOf(context.Background(), &egressv1.Egress{}, client.InNamespace("internet"))
+		Expect(err).ShouldNot(HaveOccurred())
+		ns := &corev1.Namespace{}
+		err = k8sClient.Get(context.Background(), client.ObjectKey{Name: "default"}, ns)
+		Expect(err).ShouldNot(HaveOccurred())
+		delete(ns.Labels, "egress-test")
+		err = k8sClient.Update(context.Background(), ns)
+		Expect(err).ShouldNot(HaveOccurred())
 		time.Sleep(10 * time.Millisecond)
 	})
 
@@ -383,4 +401,49 @@ var _ = Describe("Pod watcher", func() {
 			})
 		}, 5*time.Second, 1*time.Second).Should(BeTrue())
 	})
+
+	It("should enforce allowedNamespaces", func() {
+		Eventually(func() bool {
+			return reflect.DeepEqual(ft.GetPeers(), map[string]bool{
+				"10.1.1.2": true,
+				"fd01::2":  true,
+				"fd01::3":  true,
+			})
+		}).Should(BeTrue())
+
+		By("restricting the namespaces")
+		egress := &egressv1.Egress{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "internet", Name: "egress2"}, egress)
+		Expect(err).NotTo(HaveOccurred())
+		egress.Spec.AllowedNamespaces = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"egress-test": "allowed"},
+		}
+		err = k8sClient.Update(ctx, egress)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			return len(ft.GetPeers()) == 0
+		}).Should(BeTrue())
+		Expect(checkMetrics(0)).ShouldNot(HaveOccurred())
+
+		By("allowing the namespace")
+		ns := &corev1.Namespace{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: "default"}, ns)
+		Expect(err).NotTo(HaveOccurred())
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		ns.Labels["egress-test"] = "allowed"
+		err = k8sClient.Update(ctx, ns)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			return reflect.DeepEqual(ft.GetPeers(), map[string]bool{
+				"10.1.1.2": true,
+				"fd01::2":  true,
+				"fd01::3":  true,
+			})
+		}).Should(BeTrue())
+		Expect(checkMetrics(2)).ShouldNot(HaveOccurred())
+	})
 })
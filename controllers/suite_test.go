@@ -18,7 +18,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -54,7 +54,7 @@ var _ = BeforeSuite(func() {
 
 	err = clientgoscheme.AddToScheme(scheme)
 	Expect(err).NotTo(HaveOccurred())
-	err = egressv1beta1.AddToScheme(scheme)
+	err = egressv1.AddToScheme(scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
@@ -84,6 +84,11 @@ var _ = BeforeSuite(func() {
 	err = k8sClient.Create(ctx, ns)
 	Expect(err).ToNot(HaveOccurred())
 
+	ns = &corev1.Namespace{}
+	ns.Name = "internet"
+	err = k8sClient.Create(ctx, ns)
+	Expect(err).ToNot(HaveOccurred())
+
 })
 
 var _ = AfterSuite(func() {
@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/common/expfmt"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -100,6 +101,13 @@ var _ = Describe("egress-gw-cni-plugin", func() {
 		}).Should(Succeed())
 	})
 
+	It("should label NAT client pods", func() {
+		pod := &corev1.Pod{}
+		err := getResource("default", "pods", "nat-client", "", pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Labels).To(HaveKeyWithValue(constants.LabelEgressClient, "true"))
+	})
+
 	It("should allow NAT traffic over foo-over-udp tunnel", func() {
 		var fakeIP, fakeURL string
 		if testIPv6 {
@@ -0,0 +1,167 @@
+package cilium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Default location of Cilium's ConfigMap
+const (
+	DefaultConfigNamespace = "kube-system"
+	DefaultConfigName      = "cilium-config"
+)
+
+// Keys in Cilium's ConfigMap
+const (
+	keyBPFMasquerade     = "enable-bpf-masquerade"
+	keyIPv4Masquerade    = "enable-ipv4-masquerade"
+	keyIPv6Masquerade    = "enable-ipv6-masquerade"
+	keyHostLegacyRouting = "enable-host-legacy-routing"
+	keyRoutingMode       = "routing-mode"
+	keyTunnel            = "tunnel"
+)
+
+// NATMode is the way egress pods translate the source address of packets.
+type NATMode string
+
+// NAT modes
+const (
+	// NATModeAuto selects the mode by Cilium's configuration.
+	// See Config.NATMode.
+	NATModeAuto = NATMode("auto")
+
+	// NATModeMasquerade uses iptables MASQUERADE target.
+	NATModeMasquerade = NATMode("masquerade")
+
+	// NATModeSNAT uses iptables SNAT target with the fixed pod address.
+	NATModeSNAT = NATMode("snat")
+)
+
+// The ClusterRole for egress pods allows to get only the ConfigMap named
+// cilium-config.  A different name given by --cilium-config of egress-gw
+// requires a matching rule in the ClusterRole.
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get,resourceNames=cilium-config
+
+// Config is a subset of Cilium's configuration that affects egress-gw.
+type Config struct {
+	BPFMasquerade     bool
+	IPv4Masquerade    bool
+	IPv6Masquerade    bool
+	HostLegacyRouting bool
+	RoutingMode       string
+}
+
+// Load reads Cilium's ConfigMap.
+// It returns nil without an error if the ConfigMap does not exist.
+func Load(ctx context.Context, r client.Reader, key client.ObjectKey) (*Config, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if apierrors.IsForbidden(err) && key.Name != DefaultConfigName {
+			return nil, fmt.Errorf("failed to get ConfigMap %s; the egress-gw ClusterRole allows only %s: %w",
+				key.String(), DefaultConfigName, err)
+		}
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", key.String(), err)
+	}
+	return Parse(cm.Data)
+}
+
+// Parse parses the data of Cilium's ConfigMap.
+// Missing keys are filled with Cilium's defaults.
+func Parse(data map[string]string) (*Config, error) {
+	c := &Config{
+		IPv4Masquerade: true,
+		RoutingMode:    "tunnel",
+	}
+
+	boolFields := []struct {
+		key string
+		ptr *bool
+	}{
+		{keyBPFMasquerade, &c.BPFMasquerade},
+		{keyIPv4Masquerade, &c.IPv4Masquerade},
+		{keyIPv6Masquerade, &c.IPv6Masquerade},
+		{keyHostLegacyRouting, &c.HostLegacyRouting},
+	}
+	for _, f := range boolFields {
+		v, ok := data[f.key]
+		if !ok || v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %q", f.key, v)
+		}
+		*f.ptr = b
+	}
+
+	if v := data[keyRoutingMode]; v != "" {
+		c.RoutingMode = v
+	} else if v := data[keyTunnel]; v == "disabled" {
+		// older Cilium expresses native routing by disabling tunnel
+		c.RoutingMode = "native"
+	}
+
+	if !c.BPFMasquerade {
+		// eBPF host routing requires BPF masquerade
+		c.HostLegacyRouting = true
+	}
+	return c, nil
+}
+
+// NATMode returns the NAT mode to be used for `mode`.
+// `c` may be nil if Cilium is not detected.
+//
+// In auto mode, NATModeSNAT is selected if Cilium masquerades packets
+// by eBPF, or NATModeMasquerade otherwise.
+func (c *Config) NATMode(mode NATMode) NATMode {
+	if mode != NATModeAuto {
+		return mode
+	}
+	if c == nil || !c.BPFMasquerade {
+		return NATModeMasquerade
+	}
+	return NATModeSNAT
+}
+
+// Conflicts returns the descriptions of settings that conflict with
+// egress-gw running in `mode`.  `c` may be nil if Cilium is not detected.
+//
+// BPF masquerade of Cilium tracks connections by itself and conflicts
+// with MASQUERADE, which may choose a different source address for the
+// same connection.
+func (c *Config) Conflicts(mode NATMode) []string {
+	if c == nil {
+		return nil
+	}
+
+	var msgs []string
+	if c.NATMode(mode) == NATModeMasquerade && c.BPFMasquerade && (c.IPv4Masquerade || c.IPv6Masquerade) {
+		msgs = append(msgs, keyBPFMasquerade+" is true in Cilium; "+
+			"iptables MASQUERADE in egress pods conflicts with BPF masquerade, use --nat-mode=snat")
+	}
+	return msgs
+}
+
+// NewChecker returns a readiness checker that fails if settings of Cilium
+// conflict with egress-gw running in `mode`.  `c` is the configuration
+// loaded at startup, and may be nil if Cilium is not detected.
+func NewChecker(c *Config, mode NATMode) healthz.Checker {
+	msgs := c.Conflicts(mode)
+	return func(req *http.Request) error {
+		if len(msgs) == 0 {
+			return nil
+		}
+		return fmt.Errorf("conflicts with Cilium: %s", strings.Join(msgs, "; "))
+	}
+}
@@ -0,0 +1,129 @@
+package cilium
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BPFMasquerade || !c.IPv4Masquerade || c.IPv6Masquerade || !c.HostLegacyRouting || c.RoutingMode != "tunnel" {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+
+	c, err = Parse(map[string]string{
+		"enable-bpf-masquerade":      "true",
+		"enable-ipv6-masquerade":     "true",
+		"enable-host-legacy-routing": "false",
+		"tunnel":                     "disabled",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.BPFMasquerade || !c.IPv6Masquerade || c.HostLegacyRouting || c.RoutingMode != "native" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+
+	c, err = Parse(map[string]string{
+		"routing-mode": "native",
+		"tunnel":       "vxlan",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.RoutingMode != "native" {
+		t.Errorf("routing-mode should take precedence: %+v", c)
+	}
+
+	_, err = Parse(map[string]string{"enable-bpf-masquerade": "yes"})
+	if err == nil {
+		t.Error("invalid boolean should be rejected")
+	}
+}
+
+func TestNATMode(t *testing.T) {
+	var nilConfig *Config
+	if m := nilConfig.NATMode(NATModeAuto); m != NATModeMasquerade {
+		t.Errorf("auto without Cilium should be masquerade: %s", m)
+	}
+	if m := (&Config{IPv4Masquerade: true, HostLegacyRouting: true}).NATMode(NATModeAuto); m != NATModeMasquerade {
+		t.Errorf("auto with iptables masquerade of Cilium should be masquerade: %s", m)
+	}
+	if m := (&Config{BPFMasquerade: true, IPv4Masquerade: true, HostLegacyRouting: true}).NATMode(NATModeAuto); m != NATModeSNAT {
+		t.Errorf("auto with BPF masquerade of Cilium should be snat: %s", m)
+	}
+	if m := (&Config{BPFMasquerade: true, IPv4Masquerade: true}).NATMode(NATModeAuto); m != NATModeSNAT {
+		t.Errorf("auto with eBPF host routing of Cilium should be snat: %s", m)
+	}
+	if m := (&Config{BPFMasquerade: true}).NATMode(NATModeMasquerade); m != NATModeMasquerade {
+		t.Errorf("explicit mode should be kept: %s", m)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	var nilConfig *Config
+	if msgs := nilConfig.Conflicts(NATModeMasquerade); len(msgs) != 0 {
+		t.Errorf("no conflicts are expected without Cilium: %v", msgs)
+	}
+
+	// Cilium defaults
+	c, err := Parse(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []NATMode{NATModeAuto, NATModeMasquerade, NATModeSNAT} {
+		if msgs := c.Conflicts(mode); len(msgs) != 0 {
+			t.Errorf("iptables masquerade of Cilium should not conflict with %s mode: %v", mode, msgs)
+		}
+	}
+
+	// BPF masquerade with legacy host routing
+	c, err = Parse(map[string]string{
+		"enable-bpf-masquerade":      "true",
+		"enable-host-legacy-routing": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := c.Conflicts(NATModeMasquerade); len(msgs) != 1 {
+		t.Errorf("BPF masquerade should conflict with masquerade mode: %v", msgs)
+	}
+	if msgs := c.Conflicts(NATModeSNAT); len(msgs) != 0 {
+		t.Errorf("BPF masquerade should not conflict with snat mode: %v", msgs)
+	}
+	if msgs := c.Conflicts(NATModeAuto); len(msgs) != 0 {
+		t.Errorf("BPF masquerade should not conflict with auto mode: %v", msgs)
+	}
+
+	// BPF masquerade with eBPF host routing, which is Cilium's default
+	// when BPF masquerade is enabled
+	c, err = Parse(map[string]string{
+		"enable-bpf-masquerade": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := c.Conflicts(NATModeMasquerade); len(msgs) != 1 {
+		t.Errorf("BPF masquerade should conflict with masquerade mode: %v", msgs)
+	}
+	if msgs := c.Conflicts(NATModeSNAT); len(msgs) != 0 {
+		t.Errorf("eBPF host routing should not conflict with snat mode: %v", msgs)
+	}
+	if msgs := c.Conflicts(NATModeAuto); len(msgs) != 0 {
+		t.Errorf("eBPF host routing should not conflict with auto mode: %v", msgs)
+	}
+}
+
+func TestNewChecker(t *testing.T) {
+	if err := NewChecker(nil, NATModeMasquerade)(nil); err != nil {
+		t.Error("checker without Cilium should succeed", err)
+	}
+	if err := NewChecker(&Config{BPFMasquerade: true, IPv4Masquerade: true}, NATModeAuto)(nil); err != nil {
+		t.Error("checker should succeed in auto mode with BPF masquerade", err)
+	}
+	if err := NewChecker(&Config{BPFMasquerade: true, IPv4Masquerade: true}, NATModeMasquerade)(nil); err == nil {
+		t.Error("checker should report conflicts")
+	}
+}
@@ -20,9 +20,11 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	CNI_Add_FullMethodName   = "/pkg.cnirpc.CNI/Add"
-	CNI_Del_FullMethodName   = "/pkg.cnirpc.CNI/Del"
-	CNI_Check_FullMethodName = "/pkg.cnirpc.CNI/Check"
+	CNI_Add_FullMethodName    = "/pkg.cnirpc.CNI/Add"
+	CNI_Del_FullMethodName    = "/pkg.cnirpc.CNI/Del"
+	CNI_Check_FullMethodName  = "/pkg.cnirpc.CNI/Check"
+	CNI_Status_FullMethodName = "/pkg.cnirpc.CNI/Status"
+	CNI_GC_FullMethodName     = "/pkg.cnirpc.CNI/GC"
 )
 
 // CNIClient is the client API for CNI service.
@@ -32,6 +34,8 @@ type CNIClient interface {
 	Add(ctx context.Context, in *CNIArgs, opts ...grpc.CallOption) (*AddResponse, error)
 	Del(ctx context.Context, in *CNIArgs, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Check(ctx context.Context, in *CNIArgs, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Status(ctx context.Context, in *CNIArgs, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GC(ctx context.Context, in *GCArgs, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type cNIClient struct {
@@ -69,6 +73,24 @@ func (c *cNIClient) Check(ctx context.Context, in *CNIArgs, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *cNIClient) Status(ctx context.Context, in *CNIArgs, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CNI_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cNIClient) GC(ctx context.Context, in *GCArgs, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CNI_GC_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CNIServer is the server API for CNI service.
 // All implementations must embed UnimplementedCNIServer
 // for forward compatibility
@@ -76,6 +98,8 @@ type CNIServer interface {
 	Add(context.Context, *CNIArgs) (*AddResponse, error)
 	Del(context.Context, *CNIArgs) (*emptypb.Empty, error)
 	Check(context.Context, *CNIArgs) (*emptypb.Empty, error)
+	Status(context.Context, *CNIArgs) (*emptypb.Empty, error)
+	GC(context.Context, *GCArgs) (*emptypb.Empty, error)
 	mustEmbedUnimplementedCNIServer()
 }
 
@@ -92,6 +116,12 @@ func (UnimplementedCNIServer) Del(context.Context, *CNIArgs) (*emptypb.Empty, er
 func (UnimplementedCNIServer) Check(context.Context, *CNIArgs) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
 }
+func (UnimplementedCNIServer) Status(context.Context, *CNIArgs) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedCNIServer) GC(context.Context, *GCArgs) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GC not implemented")
+}
 func (UnimplementedCNIServer) mustEmbedUnimplementedCNIServer() {}
 
 // UnsafeCNIServer may be embedded to opt out of forward compatibility for this service.
@@ -159,6 +189,42 @@ func _CNI_Check_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CNI_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CNIArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNIServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNI_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNIServer).Status(ctx, req.(*CNIArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNI_GC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GCArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNIServer).GC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CNI_GC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNIServer).GC(ctx, req.(*GCArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CNI_ServiceDesc is the grpc.ServiceDesc for CNI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -178,6 +244,141 @@ var CNI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Check",
 			Handler:    _CNI_Check_Handler,
 		},
+		{
+			MethodName: "Status",
+			Handler:    _CNI_Status_Handler,
+		},
+		{
+			MethodName: "GC",
+			Handler:    _CNI_GC_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/cnirpc/cni.proto",
+}
+
+const (
+	Introspection_ListPods_FullMethodName = "/pkg.cnirpc.Introspection/ListPods"
+	Introspection_GetPod_FullMethodName   = "/pkg.cnirpc.Introspection/GetPod"
+)
+
+// IntrospectionClient is the client API for Introspection service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IntrospectionClient interface {
+	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error)
+	GetPod(ctx context.Context, in *GetPodRequest, opts ...grpc.CallOption) (*PodState, error)
+}
+
+type introspectionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIntrospectionClient(cc grpc.ClientConnInterface) IntrospectionClient {
+	return &introspectionClient{cc}
+}
+
+func (c *introspectionClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error) {
+	out := new(ListPodsResponse)
+	err := c.cc.Invoke(ctx, Introspection_ListPods_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) GetPod(ctx context.Context, in *GetPodRequest, opts ...grpc.CallOption) (*PodState, error) {
+	out := new(PodState)
+	err := c.cc.Invoke(ctx, Introspection_GetPod_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IntrospectionServer is the server API for Introspection service.
+// All implementations must embed UnimplementedIntrospectionServer
+// for forward compatibility
+type IntrospectionServer interface {
+	ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error)
+	GetPod(context.Context, *GetPodRequest) (*PodState, error)
+	mustEmbedUnimplementedIntrospectionServer()
+}
+
+// UnimplementedIntrospectionServer must be embedded to have forward compatible implementations.
+type UnimplementedIntrospectionServer struct {
+}
+
+func (UnimplementedIntrospectionServer) ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPods not implemented")
+}
+func (UnimplementedIntrospectionServer) GetPod(context.Context, *GetPodRequest) (*PodState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPod not implemented")
+}
+func (UnimplementedIntrospectionServer) mustEmbedUnimplementedIntrospectionServer() {}
+
+// UnsafeIntrospectionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IntrospectionServer will
+// result in compilation errors.
+type UnsafeIntrospectionServer interface {
+	mustEmbedUnimplementedIntrospectionServer()
+}
+
+func RegisterIntrospectionServer(s grpc.ServiceRegistrar, srv IntrospectionServer) {
+	s.RegisterService(&Introspection_ServiceDesc, srv)
+}
+
+func _Introspection_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).ListPods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Introspection_ListPods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).ListPods(ctx, req.(*ListPodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_GetPod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).GetPod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Introspection_GetPod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).GetPod(ctx, req.(*GetPodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Introspection_ServiceDesc is the grpc.ServiceDesc for Introspection service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Introspection_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pkg.cnirpc.Introspection",
+	HandlerType: (*IntrospectionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPods",
+			Handler:    _Introspection_ListPods_Handler,
+		},
+		{
+			MethodName: "GetPod",
+			Handler:    _Introspection_GetPod_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/cnirpc/cni.proto",
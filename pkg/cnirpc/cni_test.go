@@ -0,0 +1,80 @@
+package cnirpc_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc/cnirpctest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestCNIWithMock(t *testing.T) {
+	st := status.New(codes.Internal, "aaa")
+	st, err := st.WithDetails(&cnirpc.CNIError{
+		Code:    cnirpc.ErrorCode_TRY_AGAIN_LATER,
+		Msg:     "abc",
+		Details: "detail",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &cnirpctest.Server{CheckErr: st.Err()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+	}()
+
+	sockName := filepath.Join(t.TempDir(), "egress-gw-agent-mock")
+	err = s.Start(ctx, sockName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialer := &net.Dialer{}
+	dialFunc := func(ctx context.Context, a string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", a)
+	}
+	conn, err := grpc.Dial(sockName, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialFunc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := cnirpc.NewCNIClient(conn)
+	_, err = client.Check(context.Background(), &cnirpc.CNIArgs{})
+	if err == nil {
+		t.Fatal("err is expected")
+	}
+	t.Log(err)
+
+	st = status.Convert(err)
+	if st.Code() != codes.Internal {
+		t.Error(`st.Code() != codes.Internal`)
+	}
+	if st.Message() != "aaa" {
+		t.Error(`st.Message() != "aaa"`)
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatal(`len(details) != 1`, len(details))
+	}
+
+	cniErr, ok := details[0].(*cnirpc.CNIError)
+	if !ok {
+		t.Fatal(`not a CNIError`)
+	}
+
+	if cniErr.Code != cnirpc.ErrorCode_TRY_AGAIN_LATER {
+		t.Error(`cniErr.Code != CNIError_ERR_TRY_AGAIN_LATER`, cniErr.Code)
+	}
+}
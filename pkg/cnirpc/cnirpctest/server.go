@@ -0,0 +1,133 @@
+// Package cnirpctest provides a mock of egress-gw-agent for tests.
+package cnirpctest
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Server is a mock of cnirpc.CNIServer.
+// Each method returns the error set in the corresponding field, if any.
+type Server struct {
+	cnirpc.UnimplementedCNIServer
+
+	// AddResult is returned by Add if AddErr is nil.
+	AddResult []byte
+
+	AddErr    error
+	DelErr    error
+	CheckErr  error
+	StatusErr error
+	GCErr     error
+
+	// Delay delays the response of every method.
+	// If the request is canceled meanwhile, the method returns its error.
+	Delay time.Duration
+
+	mu     sync.Mutex
+	gcArgs *cnirpc.GCArgs
+}
+
+var _ cnirpc.CNIServer = &Server{}
+
+func (s *Server) Add(ctx context.Context, _ *cnirpc.CNIArgs) (*cnirpc.AddResponse, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	if s.AddErr != nil {
+		return nil, s.AddErr
+	}
+	return &cnirpc.AddResponse{Result: s.AddResult}, nil
+}
+
+func (s *Server) Del(ctx context.Context, _ *cnirpc.CNIArgs) (*emptypb.Empty, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	if s.DelErr != nil {
+		return nil, s.DelErr
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Check(ctx context.Context, _ *cnirpc.CNIArgs) (*emptypb.Empty, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	if s.CheckErr != nil {
+		return nil, s.CheckErr
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Status(ctx context.Context, _ *cnirpc.CNIArgs) (*emptypb.Empty, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	if s.StatusErr != nil {
+		return nil, s.StatusErr
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) GC(ctx context.Context, args *cnirpc.GCArgs) (*emptypb.Empty, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.gcArgs = args
+	s.mu.Unlock()
+
+	if s.GCErr != nil {
+		return nil, s.GCErr
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) wait(ctx context.Context) error {
+	if s.Delay == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-time.After(s.Delay):
+		return nil
+	}
+}
+
+// GCArgs returns the arguments of the last GC call, or nil if GC is not called.
+func (s *Server) GCArgs() *cnirpc.GCArgs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gcArgs
+}
+
+// Start starts serving on the UNIX domain socket `sockName`.
+// The server stops and removes the socket when ctx is done.
+func (s *Server) Start(ctx context.Context, sockName string) error {
+	l, err := net.Listen("unix", sockName)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	cnirpc.RegisterCNIServer(grpcServer, s)
+
+	go func() {
+		grpcServer.Serve(l)
+	}()
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+		os.Remove(sockName)
+	}()
+	return nil
+}
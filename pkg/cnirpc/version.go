@@ -0,0 +1,45 @@
+package cnirpc
+
+import (
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// CNI spec 1.1.0 uses the same Result format as 1.0.0, but the CNI library
+// in use does not know 1.1.0.  The functions in this file handle results
+// for 1.1.0 as 1.0.0.
+
+// ResultVersion returns the version of the Result format for CNI spec `cniVersion`.
+func ResultVersion(cniVersion string) string {
+	if cniVersion == "1.1.0" {
+		return "1.0.0"
+	}
+	return cniVersion
+}
+
+// ParsePrevResult is version.ParsePrevResult that accepts CNI spec 1.1.0.
+func ParsePrevResult(conf *types.NetConf) error {
+	cniVersion := conf.CNIVersion
+	defer func() {
+		conf.CNIVersion = cniVersion
+	}()
+
+	conf.CNIVersion = ResultVersion(cniVersion)
+	if v, ok := conf.RawPrevResult["cniVersion"].(string); ok {
+		conf.RawPrevResult["cniVersion"] = ResultVersion(v)
+	}
+	return version.ParsePrevResult(conf)
+}
+
+// PrintResult is types.PrintResult that accepts CNI spec 1.1.0.
+func PrintResult(result types.Result, cniVersion string) error {
+	r, err := result.GetAsVersion(ResultVersion(cniVersion))
+	if err != nil {
+		return err
+	}
+	if cur, ok := r.(*current.Result); ok {
+		cur.CNIVersion = cniVersion
+	}
+	return r.Print()
+}
@@ -0,0 +1,41 @@
+package cnirpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+func TestParsePrevResult(t *testing.T) {
+	for _, ver := range []string{"1.0.0", "1.1.0"} {
+		data := []byte(`{
+	"cniVersion": "` + ver + `",
+	"name": "k8s",
+	"type": "egress-gw",
+	"prevResult": {
+		"cniVersion": "` + ver + `",
+		"ips": [{"address": "10.1.0.5/32"}]
+	}
+}`)
+		conf := &types.NetConf{}
+		if err := json.Unmarshal(data, conf); err != nil {
+			t.Fatal(err)
+		}
+		if err := ParsePrevResult(conf); err != nil {
+			t.Fatal(ver, err)
+		}
+		if conf.CNIVersion != ver {
+			t.Error("CNIVersion should be kept", conf.CNIVersion)
+		}
+
+		result, err := current.NewResultFromResult(conf.PrevResult)
+		if err != nil {
+			t.Fatal(ver, err)
+		}
+		if len(result.IPs) != 1 || result.IPs[0].Address.String() != "10.1.0.5/32" {
+			t.Error("unexpected prevResult", result)
+		}
+	}
+}
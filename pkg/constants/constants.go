@@ -3,6 +3,9 @@ package constants
 // AnnEgressPrefix annotation keys
 const (
 	AnnEgressPrefix = "egress.ysksuzuki.com/"
+
+	// AnnClusterEgress is the annotation key to specify ClusterEgress names.
+	AnnClusterEgress = "egress.ysksuzuki.com"
 )
 
 // Keys in CNI_ARGS
@@ -17,6 +20,9 @@ const (
 	LabelAppName      = "app.kubernetes.io/name"
 	LabelAppInstance  = "app.kubernetes.io/instance"
 	LabelAppComponent = "app.kubernetes.io/component"
+
+	// LabelEgressClient is put on pods having egress annotations by the webhook.
+	LabelEgressClient = "egress.ysksuzuki.com/client"
 )
 
 // RBAC resource names
@@ -30,9 +36,14 @@ const (
 
 // Environment variables
 const (
-	EnvAddresses    = "EGRESS_GW_POD_ADDRESSES"
-	EnvPodNamespace = "EGRESS_GW_POD_NAMESPACE"
-	EnvPodName      = "EGRESS_GW_POD_NAME"
-	EnvEgressName   = "EGRESS_GW_NAME"
+	EnvAddresses     = "EGRESS_GW_POD_ADDRESSES"
+	EnvPodNamespace  = "EGRESS_GW_POD_NAMESPACE"
+	EnvPodName       = "EGRESS_GW_POD_NAME"
+	EnvEgressName    = "EGRESS_GW_NAME"
+	EnvClusterEgress = "EGRESS_GW_CLUSTER_EGRESS"
+	EnvInterface     = "EGRESS_GW_INTERFACE"
+	EnvNetwork       = "EGRESS_GW_NETWORK_ATTACHMENT"
+	EnvNextHops      = "EGRESS_GW_NEXT_HOPS"
+	EnvNodeName      = "EGRESS_GW_NODE_NAME"
 )
 const MetricsNS = "egressgw"
@@ -3,3 +3,11 @@ package constants
 // DefaultSocketPath is the default UNIX domain socket filename
 // for gRPC between egress-gw and egress-gw-agent.
 const DefaultSocketPath = "/run/egress-gw.sock"
+
+// DefaultStateDir is the default directory where egress-gw-agent
+// records pods it has configured.
+const DefaultStateDir = "/run/egress-gw"
+
+// DefaultBPFPinDir is the default directory in bpffs where egress-gw-agent
+// pins maps of the eBPF datapath.
+const DefaultBPFPinDir = "/sys/fs/bpf/egress-gw"
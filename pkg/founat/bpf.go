@@ -0,0 +1,663 @@
+package founat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/rlimit"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// BPF datapath parameters
+const (
+	bpfFilterName = "egress-gw"
+	bpfMaxEntries = 1024
+
+	// bpfTableID is the routing table to lower the MTU of routes to
+	// destinations encapsulated by the program.
+	bpfTableID  = 116
+	bpfRulePrio = 1850
+
+	// offsets in struct __sk_buff
+	skbLenOff      = 0
+	skbProtocolOff = 16
+
+	ethHeaderLen   = 14
+	ipv4DstOff     = ethHeaderLen + 16
+	encapHeaderLen = 28 // outer IPv4 header (20) + UDP header (8)
+
+	bpfActionPass  = 0
+	bpfActionEncap = 1
+)
+
+// bpfKey is the key of the LPM trie that maps IPv4 destinations to gateways.
+type bpfKey struct {
+	PrefixLen uint32
+	Addr      [4]byte
+}
+
+// bpfValue is the value of the LPM trie.
+//
+// Header is a template of the outer IPv4 and UDP headers for Foo-over-UDP.
+// The program fills in the length fields, the IPv4 header checksum and the
+// UDP source port of the template for each packet.  CsumBase is the one's
+// complement sum of the IPv4 header template to calculate the checksum.
+type bpfValue struct {
+	Action   uint32
+	CsumBase uint32
+	Header   [encapHeaderLen]byte
+}
+
+// BPFClient represents the interface for the eBPF datapath of NAT client.
+//
+// Unlike NatClient, BPFClient does not need policy routing nor tunnel devices
+// for each gateway.  A TC program attached to the egress hook of the pod
+// interface looks up the destination address in a BPF map and encapsulates
+// packets in Foo-over-UDP by itself.
+//
+// The program does not change routing, so the kernel would send packets that
+// exceed the link MTU after encapsulation.  To avoid this, BPFClient adds
+// routes to the destinations with the MTU lowered by the encapsulation
+// overhead, as FoU tunnel devices do.  The MTU of the routes also limits
+// TCP MSS.
+//
+// BPFClient supports only IPv4.  Use NatClient for IPv6.
+//
+// If a pin path is given, the map of destinations is pinned to bpffs so
+// that another process can update the destinations later by calling Open
+// instead of Init.  The pin should be removed by UnpinBPF when the pod is
+// deleted.  Without a pin path, the program and the map live as long as
+// the filter attached to the interface, and the destinations can be changed
+// only by calling Init again and adding all the destinations.
+type BPFClient interface {
+	// Init loads the program and attaches it to the interface.
+	// This can be called again to re-initialize the datapath.
+	Init() error
+
+	// Open opens the map pinned by Init to update destinations.
+	// It returns an error satisfying errors.Is(err, os.ErrNotExist)
+	// if the map is not pinned.
+	Open() error
+
+	// AddEgress steers packets destined to subnets to the gateway.
+	AddEgress(gw net.IP, subnets []*net.IPNet) error
+
+	// DelEgress stops steering packets destined to subnets.
+	DelEgress(subnets []*net.IPNet) error
+
+	// Close releases the resources held by this client.
+	// The attached program keeps working after Close.
+	Close() error
+}
+
+// NewBPFClient creates a BPFClient.
+//
+// `iface` is the name of the pod interface.  `port` is the UDP port number of
+// Foo-over-UDP.  `ipv4` is the IPv4 address of the client pod.
+//
+// `podNodeNet` has the same meaning as that of NewNatClient.
+//
+// `pinPath` is the path in bpffs to pin the map.  It can be empty.
+func NewBPFClient(iface string, port int, ipv4 net.IP, podNodeNet []*net.IPNet, pinPath string) BPFClient {
+	if ipv4 == nil || ipv4.To4() == nil {
+		panic("invalid IPv4 address")
+	}
+
+	var v4priv []*net.IPNet
+	for _, n := range podNodeNet {
+		if n.IP.To4() != nil {
+			v4priv = append(v4priv, n)
+		}
+	}
+	if len(podNodeNet) == 0 {
+		v4priv = v4PrivateList
+	}
+
+	return &bpfClient{
+		iface:   iface,
+		port:    port,
+		ipv4:    ipv4.To4(),
+		v4priv:  v4priv,
+		pinPath: pinPath,
+	}
+}
+
+type bpfClient struct {
+	iface   string
+	port    int
+	ipv4    net.IP
+	v4priv  []*net.IPNet
+	pinPath string
+
+	mu   sync.Mutex
+	dsts *ebpf.Map
+
+	// the route template to destinations
+	route *netlink.Route
+}
+
+func (c *bpfClient) Init() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := rlimit.RemoveMemlock(); err != nil {
+		return fmt.Errorf("failed to remove memlock limit: %w", err)
+	}
+
+	// egress_ipip4 receives packets returned from egress pods.
+	if err := setupIPIPDevices(true, false); err != nil {
+		return fmt.Errorf("netlink: failed to setup ipip device: %w", err)
+	}
+
+	if c.dsts != nil {
+		c.dsts.Close()
+		c.dsts = nil
+	}
+	if err := UnpinBPF(c.pinPath); err != nil {
+		return err
+	}
+	dsts, err := c.newDstsMap()
+	if err != nil {
+		return err
+	}
+
+	prog, err := loadEncapProgram(dsts)
+	if err != nil {
+		dsts.Close()
+		return err
+	}
+	defer prog.Close()
+
+	if err := attachTCEgress(c.iface, prog); err != nil {
+		dsts.Close()
+		return err
+	}
+
+	route, err := c.setupRouting()
+	if err != nil {
+		dsts.Close()
+		return err
+	}
+
+	if c.pinPath != "" {
+		if err := os.MkdirAll(filepath.Dir(c.pinPath), 0700); err != nil {
+			dsts.Close()
+			return fmt.Errorf("bpf: failed to create the directory to pin map: %w", err)
+		}
+		if err := dsts.Pin(c.pinPath); err != nil {
+			dsts.Close()
+			return fmt.Errorf("bpf: failed to pin map to %s: %w", c.pinPath, err)
+		}
+	}
+
+	c.dsts = dsts
+	c.route = route
+	return nil
+}
+
+func (c *bpfClient) Open() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pinPath == "" {
+		return fmt.Errorf("bpf: no pin path: %w", os.ErrNotExist)
+	}
+
+	if c.dsts != nil {
+		c.dsts.Close()
+		c.dsts = nil
+	}
+	dsts, err := ebpf.LoadPinnedMap(c.pinPath, nil)
+	if err != nil {
+		return fmt.Errorf("bpf: failed to load pinned map %s: %w", c.pinPath, err)
+	}
+
+	link, err := netlink.LinkByName(c.iface)
+	if err != nil {
+		dsts.Close()
+		return fmt.Errorf("netlink: failed to get link %s: %w", c.iface, err)
+	}
+	route, err := c.routeTemplate(link)
+	if err != nil {
+		dsts.Close()
+		return err
+	}
+
+	c.dsts = dsts
+	c.route = route
+	return nil
+}
+
+// UnpinBPF removes the map pinned at `pinPath`.  The map is freed when
+// the program using it is detached.  It is not an error if nothing is
+// pinned at `pinPath` or `pinPath` is empty.
+func UnpinBPF(pinPath string) error {
+	if pinPath == "" {
+		return nil
+	}
+	err := os.Remove(pinPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("bpf: failed to unpin %s: %w", pinPath, err)
+	}
+	return nil
+}
+
+// setupRouting clears the routing table for destinations and adds the rule
+// to look it up.  It returns the template of routes to destinations.
+func (c *bpfClient) setupRouting() (*netlink.Route, error) {
+	defaultGW := &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+
+	rules, err := netlink.RuleList(netlink.FAMILY_V4)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: rule list failed: %w", err)
+	}
+	for _, r := range rules {
+		if r.Priority != bpfRulePrio {
+			continue
+		}
+		if r.Dst == nil {
+			// workaround for a library issue
+			r.Dst = defaultGW
+		}
+		if err := netlink.RuleDel(&r); err != nil {
+			return nil, fmt.Errorf("netlink: failed to delete a rule: %+v, %w", r, err)
+		}
+	}
+
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: bpfTableID}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: route list failed: %w", err)
+	}
+	for _, r := range routes {
+		if r.Dst == nil {
+			// workaround for a library issue
+			r.Dst = defaultGW
+		}
+		if err := netlink.RouteDel(&r); err != nil {
+			return nil, fmt.Errorf("netlink: failed to delete a route in table %d: %+v, %w", bpfTableID, r, err)
+		}
+	}
+
+	link, err := netlink.LinkByName(c.iface)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: failed to get link %s: %w", c.iface, err)
+	}
+	route, err := c.routeTemplate(link)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link local and private networks are not encapsulated unless
+	// narrower destinations are added by AddEgress.
+	for _, n := range append([]*net.IPNet{v4LinkLocal}, c.v4priv...) {
+		if err := netlink.RouteAdd(newThrowRoute(n)); err != nil {
+			return nil, fmt.Errorf("netlink: failed to add throw route to %s: %w", n.String(), err)
+		}
+	}
+
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V4
+	rule.Table = bpfTableID
+	rule.Priority = bpfRulePrio
+	if err := netlink.RuleAdd(rule); err != nil {
+		return nil, fmt.Errorf("netlink: failed to add rule for table %d: %w", bpfTableID, err)
+	}
+	return route, nil
+}
+
+func newThrowRoute(n *net.IPNet) *netlink.Route {
+	return &netlink.Route{
+		Table:    bpfTableID,
+		Dst:      n,
+		Type:     unix.RTN_THROW,
+		Protocol: ncProtocolID,
+	}
+}
+
+// routeTemplate returns the template of routes to destinations, which is
+// the same as the default route of `link` except for the MTU.
+func (c *bpfClient) routeTemplate(link netlink.Link) (*netlink.Route, error) {
+	route := &netlink.Route{
+		Table:     bpfTableID,
+		LinkIndex: link.Attrs().Index,
+		Protocol:  ncProtocolID,
+		MTU:       link.Attrs().MTU,
+	}
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{LinkIndex: link.Attrs().Index}, netlink.RT_FILTER_OIF)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: route list failed: %w", err)
+	}
+	for _, r := range routes {
+		if r.Dst != nil {
+			if ones, _ := r.Dst.Mask.Size(); ones != 0 {
+				continue
+			}
+		}
+		route.Gw = r.Gw
+		route.Flags = r.Flags
+		if r.MTU > 0 {
+			route.MTU = r.MTU
+		}
+		break
+	}
+	route.MTU -= encapHeaderLen
+	return route, nil
+}
+
+// newDstsMap creates the LPM trie of destinations.
+func (c *bpfClient) newDstsMap() (*ebpf.Map, error) {
+	dsts, err := ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "egress_dsts",
+		Type:       ebpf.LPMTrie,
+		KeySize:    uint32(binary.Size(bpfKey{})),
+		ValueSize:  uint32(binary.Size(bpfValue{})),
+		MaxEntries: bpfMaxEntries,
+		Flags:      unix.BPF_F_NO_PREALLOC,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bpf: failed to create map: %w", err)
+	}
+
+	// Link local and private networks are not routed to egress pods
+	// unless narrower destinations are added by AddEgress.
+	for _, n := range append([]*net.IPNet{v4LinkLocal}, c.v4priv...) {
+		if err := dsts.Put(newBPFKey(n), &bpfValue{Action: bpfActionPass}); err != nil {
+			dsts.Close()
+			return nil, fmt.Errorf("bpf: failed to add %s to map: %w", n.String(), err)
+		}
+	}
+	return dsts, nil
+}
+
+func loadEncapProgram(dsts *ebpf.Map) (*ebpf.Program, error) {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Name:         "egress_gw",
+		Type:         ebpf.SchedCLS,
+		License:      "Apache-2.0",
+		Instructions: encapProgram(dsts.FD()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bpf: failed to load program: %w", err)
+	}
+	return prog, nil
+}
+
+func attachTCEgress(iface string, prog *ebpf.Program) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("netlink: failed to get link %s: %w", iface, err)
+	}
+
+	qdisc := &netlink.GenericQdisc{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(0xffff, 0),
+			Parent:    netlink.HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := netlink.QdiscReplace(qdisc); err != nil {
+		return fmt.Errorf("netlink: failed to add clsact qdisc to %s: %w", iface, err)
+	}
+
+	filter := &netlink.BpfFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    netlink.HANDLE_MIN_EGRESS,
+			Handle:    netlink.MakeHandle(0, 1),
+			Protocol:  unix.ETH_P_ALL,
+			Priority:  1,
+		},
+		Fd:           prog.FD(),
+		Name:         bpfFilterName,
+		DirectAction: true,
+	}
+	if err := netlink.FilterReplace(filter); err != nil {
+		return fmt.Errorf("netlink: failed to attach bpf filter to %s: %w", iface, err)
+	}
+	return nil
+}
+
+func (c *bpfClient) AddEgress(gw net.IP, subnets []*net.IPNet) error {
+	gw4 := gw.To4()
+	if gw4 == nil {
+		return ErrIPFamilyMismatch
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dsts == nil {
+		return fmt.Errorf("bpf: not initialized")
+	}
+
+	v := c.newEncapValue(gw4)
+	for _, n := range subnets {
+		if n.IP.To4() == nil {
+			continue
+		}
+		if err := c.dsts.Put(newBPFKey(n), v); err != nil {
+			return fmt.Errorf("bpf: failed to add %s to map: %w", n.String(), err)
+		}
+
+		r := *c.route
+		r.Dst = n
+		if err := netlink.RouteReplace(&r); err != nil {
+			return fmt.Errorf("netlink: failed to add route to %s: %w", n.String(), err)
+		}
+	}
+	return nil
+}
+
+func (c *bpfClient) DelEgress(subnets []*net.IPNet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dsts == nil {
+		return fmt.Errorf("bpf: not initialized")
+	}
+
+	reserved := append([]*net.IPNet{v4LinkLocal}, c.v4priv...)
+	for _, n := range subnets {
+		if n.IP.To4() == nil {
+			continue
+		}
+
+		// restore the entry of a link local or private network
+		// instead of deleting it.
+		if isReserved(n, reserved) {
+			if err := c.dsts.Put(newBPFKey(n), &bpfValue{Action: bpfActionPass}); err != nil {
+				return fmt.Errorf("bpf: failed to add %s to map: %w", n.String(), err)
+			}
+			if err := netlink.RouteReplace(newThrowRoute(n)); err != nil {
+				return fmt.Errorf("netlink: failed to add throw route to %s: %w", n.String(), err)
+			}
+			continue
+		}
+
+		err := c.dsts.Delete(newBPFKey(n))
+		if err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("bpf: failed to delete %s from map: %w", n.String(), err)
+		}
+		err = netlink.RouteDel(&netlink.Route{Table: bpfTableID, Dst: n})
+		if err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("netlink: failed to delete route to %s: %w", n.String(), err)
+		}
+	}
+	return nil
+}
+
+func isReserved(n *net.IPNet, reserved []*net.IPNet) bool {
+	for _, r := range reserved {
+		if n.IP.Equal(r.IP) && bytes.Equal(n.Mask, r.Mask) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *bpfClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dsts == nil {
+		return nil
+	}
+	err := c.dsts.Close()
+	c.dsts = nil
+	return err
+}
+
+func newBPFKey(n *net.IPNet) *bpfKey {
+	ones, _ := n.Mask.Size()
+	k := &bpfKey{PrefixLen: uint32(ones)}
+	copy(k.Addr[:], n.IP.To4())
+	return k
+}
+
+func (c *bpfClient) newEncapValue(gw net.IP) *bpfValue {
+	v := &bpfValue{Action: bpfActionEncap}
+	h := v.Header[:]
+	h[0] = 0x45 // version 4, IHL 5
+	h[8] = 225  // TTL; the same as FoU tunnel devices
+	h[9] = unix.IPPROTO_UDP
+	copy(h[12:16], c.ipv4)
+	copy(h[16:20], gw)
+	binary.BigEndian.PutUint16(h[22:24], uint16(c.port))
+
+	// total length and checksum are zero here
+	var sum uint32
+	for i := 0; i < 20; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(h[i : i+2]))
+	}
+	v.CsumBase = sum
+	return v
+}
+
+// encapProgram returns a TC program that encapsulates IPv4 packets in
+// Foo-over-UDP according to the LPM trie of `dstsFD`.
+//
+// The program uses the stack as follows:
+//
+//	fp-8:  bpfKey
+//	fp-40: outer IPv4 and UDP headers (28 bytes)
+func encapProgram(dstsFD int) asm.Instructions {
+	const (
+		keyOff    = -8
+		hdrOff    = -40
+		totLenOff = hdrOff + 2
+		csumOff   = hdrOff + 10
+		sportOff  = hdrOff + 20
+		udpLenOff = hdrOff + 24
+
+		valueHeaderOff = 8
+		valueCsumOff   = 4
+	)
+
+	return asm.Instructions{
+		// R6 = skb
+		asm.Mov.Reg(asm.R6, asm.R1),
+
+		// pass non-IPv4 packets
+		asm.LoadMem(asm.R2, asm.R6, skbProtocolOff, asm.Word),
+		asm.HostTo(asm.BE, asm.R2, asm.Half),
+		asm.JNE.Imm(asm.R2, unix.ETH_P_IP, "pass"),
+
+		// look up the destination address
+		asm.StoreImm(asm.RFP, keyOff, 32, asm.Word),
+		asm.Mov.Reg(asm.R1, asm.R6),
+		asm.Mov.Imm(asm.R2, ipv4DstOff),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, keyOff+4),
+		asm.Mov.Imm(asm.R4, 4),
+		asm.FnSkbLoadBytes.Call(),
+		asm.JNE.Imm(asm.R0, 0, "pass"),
+		asm.LoadMapPtr(asm.R1, dstsFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, keyOff),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "pass"),
+		asm.Mov.Reg(asm.R7, asm.R0),
+		asm.LoadMem(asm.R1, asm.R7, 0, asm.Word),
+		asm.JNE.Imm(asm.R1, bpfActionEncap, "pass"),
+
+		// R8 = the length of the original packet
+		asm.LoadMem(asm.R8, asm.R6, skbLenOff, asm.Word),
+
+		// copy the header template onto the stack
+		asm.LoadMem(asm.R1, asm.R7, valueHeaderOff, asm.DWord),
+		asm.StoreMem(asm.RFP, hdrOff, asm.R1, asm.DWord),
+		asm.LoadMem(asm.R1, asm.R7, valueHeaderOff+8, asm.DWord),
+		asm.StoreMem(asm.RFP, hdrOff+8, asm.R1, asm.DWord),
+		asm.LoadMem(asm.R1, asm.R7, valueHeaderOff+16, asm.DWord),
+		asm.StoreMem(asm.RFP, hdrOff+16, asm.R1, asm.DWord),
+		asm.LoadMem(asm.R1, asm.R7, valueHeaderOff+24, asm.Word),
+		asm.StoreMem(asm.RFP, hdrOff+24, asm.R1, asm.Word),
+
+		// IPv4 total length = len - ETH_HLEN + 28
+		asm.Mov.Reg(asm.R2, asm.R8),
+		asm.Add.Imm(asm.R2, encapHeaderLen-ethHeaderLen),
+		asm.Mov.Reg(asm.R1, asm.R2),
+		asm.HostTo(asm.BE, asm.R1, asm.Half),
+		asm.StoreMem(asm.RFP, totLenOff, asm.R1, asm.Half),
+
+		// IPv4 header checksum
+		asm.LoadMem(asm.R1, asm.R7, valueCsumOff, asm.Word),
+		asm.Add.Reg(asm.R1, asm.R2),
+		asm.Mov.Reg(asm.R3, asm.R1),
+		asm.RSh.Imm(asm.R3, 16),
+		asm.And.Imm(asm.R1, 0xffff),
+		asm.Add.Reg(asm.R1, asm.R3),
+		asm.Mov.Reg(asm.R3, asm.R1),
+		asm.RSh.Imm(asm.R3, 16),
+		asm.And.Imm(asm.R1, 0xffff),
+		asm.Add.Reg(asm.R1, asm.R3),
+		asm.Xor.Imm(asm.R1, 0xffff),
+		asm.HostTo(asm.BE, asm.R1, asm.Half),
+		asm.StoreMem(asm.RFP, csumOff, asm.R1, asm.Half),
+
+		// UDP length = IPv4 total length - 20
+		asm.Add.Imm(asm.R2, -20),
+		asm.HostTo(asm.BE, asm.R2, asm.Half),
+		asm.StoreMem(asm.RFP, udpLenOff, asm.R2, asm.Half),
+
+		// UDP source port is chosen from the flow hash like the kernel does
+		asm.Mov.Reg(asm.R1, asm.R6),
+		asm.FnGetHashRecalc.Call(),
+		asm.And.Imm(asm.R0, 0x3fff),
+		asm.Or.Imm(asm.R0, 0xc000),
+		asm.HostTo(asm.BE, asm.R0, asm.Half),
+		asm.StoreMem(asm.RFP, sportOff, asm.R0, asm.Half),
+
+		// make room for the outer headers and write them
+		asm.Mov.Reg(asm.R1, asm.R6),
+		asm.Mov.Imm(asm.R2, encapHeaderLen),
+		asm.Mov.Imm(asm.R3, unix.BPF_ADJ_ROOM_MAC),
+		asm.Mov.Imm(asm.R4, unix.BPF_F_ADJ_ROOM_ENCAP_L3_IPV4|unix.BPF_F_ADJ_ROOM_ENCAP_L4_UDP),
+		asm.FnSkbAdjustRoom.Call(),
+		asm.JNE.Imm(asm.R0, 0, "drop"),
+		asm.Mov.Reg(asm.R1, asm.R6),
+		asm.Mov.Imm(asm.R2, ethHeaderLen),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, hdrOff),
+		asm.Mov.Imm(asm.R4, encapHeaderLen),
+		asm.Mov.Imm(asm.R5, 0),
+		asm.FnSkbStoreBytes.Call(),
+		asm.JNE.Imm(asm.R0, 0, "drop"),
+
+		asm.Mov.Imm(asm.R0, 0).WithSymbol("pass"), // TC_ACT_OK
+		asm.Return(),
+
+		asm.Mov.Imm(asm.R0, 2).WithSymbol("drop"), // TC_ACT_SHOT
+		asm.Return(),
+	}
+}
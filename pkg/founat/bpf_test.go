@@ -0,0 +1,346 @@
+package founat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cilium/ebpf/rlimit"
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestBPFClient(t *testing.T) {
+	cNS, err := ns.GetNS("/run/netns/test-bpf-v4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cNS.Close()
+
+	err = cNS.Do(func(ns.NetNS) error {
+		err := netlink.LinkAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}})
+		if err != nil {
+			return fmt.Errorf("failed to add eth0: %w", err)
+		}
+		link, err := netlink.LinkByName("eth0")
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+		addr, _ := netlink.ParseAddr("10.1.1.1/24")
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return err
+		}
+		err = netlink.RouteAdd(&netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Gw:        net.ParseIP("10.1.1.254"),
+			MTU:       1450,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add the default route: %w", err)
+		}
+
+		pinPath := "/sys/fs/bpf/egress-gw-test/dsts"
+		defer os.RemoveAll(filepath.Dir(pinPath))
+		bc := NewBPFClient("eth0", 5555, net.ParseIP("10.1.1.1"), nil, pinPath)
+		if err := bc.Init(); err != nil {
+			return err
+		}
+		defer bc.Close()
+
+		filters, err := netlink.FilterList(link, netlink.HANDLE_MIN_EGRESS)
+		if err != nil {
+			return err
+		}
+		if len(filters) != 1 {
+			return fmt.Errorf("expected 1 filter, got %d", len(filters))
+		}
+		if f, ok := filters[0].(*netlink.BpfFilter); !ok || !f.DirectAction {
+			return fmt.Errorf("unexpected filter: %+v", filters[0])
+		}
+
+		if _, err := netlink.LinkByName("egress_ipip4"); err != nil {
+			return fmt.Errorf("failed to get egress_ipip4: %w", err)
+		}
+
+		_, subnet1, _ := net.ParseCIDR("192.168.1.0/24")
+		_, subnet2, _ := net.ParseCIDR("0.0.0.0/0")
+		_, subnet3, _ := net.ParseCIDR("fd02::/64")
+		err = bc.AddEgress(net.ParseIP("10.100.0.1"), []*net.IPNet{subnet1, subnet2, subnet3})
+		if err != nil {
+			return err
+		}
+
+		err = bc.AddEgress(net.ParseIP("fd02::1"), []*net.IPNet{subnet3})
+		if !errors.Is(err, ErrIPFamilyMismatch) {
+			return fmt.Errorf("expected ErrIPFamilyMismatch, got %v", err)
+		}
+
+		dsts := bc.(*bpfClient).dsts
+		lookup := func(addr string) (*bpfValue, error) {
+			k := newBPFKey(&net.IPNet{IP: net.ParseIP(addr), Mask: net.CIDRMask(32, 32)})
+			v := &bpfValue{}
+			if err := dsts.Lookup(k, v); err != nil {
+				return nil, fmt.Errorf("failed to lookup %s: %w", addr, err)
+			}
+			return v, nil
+		}
+
+		testCases := []struct {
+			addr   string
+			action uint32
+		}{
+			{"192.168.1.10", bpfActionEncap},
+			{"192.168.2.10", bpfActionPass},
+			{"10.2.3.4", bpfActionPass},
+			{"169.254.1.1", bpfActionPass},
+			{"8.8.8.8", bpfActionEncap},
+		}
+		for _, tc := range testCases {
+			v, err := lookup(tc.addr)
+			if err != nil {
+				return err
+			}
+			if v.Action != tc.action {
+				return fmt.Errorf("unexpected action for %s: %d", tc.addr, v.Action)
+			}
+		}
+
+		v, err := lookup("8.8.8.8")
+		if err != nil {
+			return err
+		}
+		if !net.IP(v.Header[12:16]).Equal(net.ParseIP("10.1.1.1")) {
+			return fmt.Errorf("wrong source address in header: %v", v.Header[12:16])
+		}
+		if !net.IP(v.Header[16:20]).Equal(net.ParseIP("10.100.0.1")) {
+			return fmt.Errorf("wrong destination address in header: %v", v.Header[16:20])
+		}
+		if v.Header[22] != 0x15 || v.Header[23] != 0xb3 {
+			return fmt.Errorf("wrong destination port in header: %v", v.Header[22:24])
+		}
+
+		rules, err := netlink.RuleList(netlink.FAMILY_V4)
+		if err != nil {
+			return err
+		}
+		var foundRule bool
+		for _, r := range rules {
+			if r.Priority == 1850 && r.Table == 116 {
+				foundRule = true
+			}
+		}
+		if !foundRule {
+			return errors.New("no rule for table 116")
+		}
+
+		routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: 116}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return err
+		}
+		var encapRoutes, throwRoutes int
+		for _, r := range routes {
+			if r.Type == unix.RTN_THROW {
+				throwRoutes++
+				continue
+			}
+			encapRoutes++
+			if r.MTU != 1450-28 {
+				return fmt.Errorf("wrong MTU of the route to %v: %d", r.Dst, r.MTU)
+			}
+			if !r.Gw.Equal(net.ParseIP("10.1.1.254")) {
+				return fmt.Errorf("wrong gateway of the route to %v: %v", r.Dst, r.Gw)
+			}
+		}
+		if encapRoutes != 2 {
+			return fmt.Errorf("expected 2 routes to destinations, got %d", encapRoutes)
+		}
+		if throwRoutes != 4 {
+			return fmt.Errorf("expected 4 throw routes, got %d", throwRoutes)
+		}
+
+		// another client can update destinations through the pinned map
+		bc2 := NewBPFClient("eth0", 5555, net.ParseIP("10.1.1.1"), nil, pinPath)
+		if err := bc2.Open(); err != nil {
+			return err
+		}
+		defer bc2.Close()
+		_, subnet4, _ := net.ParseCIDR("10.0.0.0/8")
+		if err := bc2.AddEgress(net.ParseIP("10.100.0.1"), []*net.IPNet{subnet4}); err != nil {
+			return err
+		}
+		if err := bc2.DelEgress([]*net.IPNet{subnet1, subnet4}); err != nil {
+			return err
+		}
+		for _, addr := range []string{"192.168.1.10", "10.2.3.4"} {
+			v, err := lookup(addr)
+			if err != nil {
+				return err
+			}
+			if v.Action != bpfActionPass {
+				return fmt.Errorf("unexpected action for %s after DelEgress: %d", addr, v.Action)
+			}
+		}
+		routes, err = netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: 116}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return err
+		}
+		if len(routes) != 5 {
+			return fmt.Errorf("expected 5 routes after DelEgress, got %d", len(routes))
+		}
+
+		// re-initialization should succeed
+		if err := bc.Init(); err != nil {
+			return err
+		}
+		routes, err = netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: 116}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return err
+		}
+		if len(routes) != 4 {
+			return fmt.Errorf("expected only throw routes after re-initialization, got %d routes", len(routes))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// newTestPacket returns an Ethernet frame of an IPv4 UDP packet.
+func newTestPacket(src, dst string) []byte {
+	payload := []byte("hello, egress")
+	pkt := make([]byte, ethHeaderLen+20+8+len(payload))
+
+	binary.BigEndian.PutUint16(pkt[12:14], unix.ETH_P_IP)
+	ip := pkt[ethHeaderLen:]
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)))
+	ip[8] = 64
+	ip[9] = unix.IPPROTO_UDP
+	copy(ip[12:16], net.ParseIP(src).To4())
+	copy(ip[16:20], net.ParseIP(dst).To4())
+	binary.BigEndian.PutUint16(ip[10:12], ipv4Checksum(ip[:20]))
+	udp := ip[20:]
+	binary.BigEndian.PutUint16(udp[0:2], 10000)
+	binary.BigEndian.PutUint16(udp[2:4], 80)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(len(udp)))
+	copy(udp[8:], payload)
+	return pkt
+}
+
+func ipv4Checksum(h []byte) uint16 {
+	var sum uint32
+	for i := 0; i < len(h); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(h[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+func TestBPFProgram(t *testing.T) {
+	if err := rlimit.RemoveMemlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewBPFClient("eth0", 5555, net.ParseIP("10.1.1.1"), nil, "").(*bpfClient)
+	dsts, err := c.newDstsMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.dsts = dsts
+	defer c.Close()
+
+	prog, err := loadEncapProgram(dsts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.Close()
+
+	// AddEgress also adds routes, so fill the map directly.
+	_, subnet, _ := net.ParseCIDR("0.0.0.0/0")
+	if err := dsts.Put(newBPFKey(subnet), c.newEncapValue(net.ParseIP("10.100.0.1").To4())); err != nil {
+		t.Fatal(err)
+	}
+
+	// packets to the private network pass through as they are.
+	in := newTestPacket("10.1.1.1", "10.2.3.4")
+	ret, out, err := prog.Test(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != 0 {
+		t.Errorf("unexpected verdict for a private destination: %d", ret)
+	}
+	if !bytes.Equal(out, in) {
+		t.Error("packets to a private destination should not be modified")
+	}
+
+	// non-IPv4 packets pass through as they are.
+	in6 := make([]byte, 64)
+	binary.BigEndian.PutUint16(in6[12:14], unix.ETH_P_IPV6)
+	ret, out, err = prog.Test(in6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != 0 || !bytes.Equal(out, in6) {
+		t.Errorf("IPv6 packets should pass through: verdict %d", ret)
+	}
+
+	// packets to the internet are encapsulated in FoU and sent as is.
+	in = newTestPacket("10.1.1.1", "8.8.8.8")
+	ret, out, err = prog.Test(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret != 0 {
+		t.Errorf("encapsulated packets should be passed to the interface: verdict %d", ret)
+	}
+	if len(out) != len(in)+encapHeaderLen {
+		t.Fatalf("unexpected length of the encapsulated packet: %d", len(out))
+	}
+	if !bytes.Equal(out[:ethHeaderLen], in[:ethHeaderLen]) {
+		t.Error("the Ethernet header should be kept")
+	}
+
+	outer := out[ethHeaderLen : ethHeaderLen+20]
+	if outer[0] != 0x45 {
+		t.Errorf("unexpected version and IHL: %#x", outer[0])
+	}
+	if l := binary.BigEndian.Uint16(outer[2:4]); int(l) != len(in)-ethHeaderLen+encapHeaderLen {
+		t.Errorf("unexpected total length: %d", l)
+	}
+	if outer[8] != 225 || outer[9] != unix.IPPROTO_UDP {
+		t.Errorf("unexpected TTL or protocol: %d, %d", outer[8], outer[9])
+	}
+	if !net.IP(outer[12:16]).Equal(net.ParseIP("10.1.1.1")) || !net.IP(outer[16:20]).Equal(net.ParseIP("10.100.0.1")) {
+		t.Errorf("unexpected addresses: %v -> %v", net.IP(outer[12:16]), net.IP(outer[16:20]))
+	}
+	if ipv4Checksum(outer) != 0 {
+		t.Errorf("invalid header checksum: %#x", binary.BigEndian.Uint16(outer[10:12]))
+	}
+
+	udp := out[ethHeaderLen+20 : ethHeaderLen+encapHeaderLen]
+	if sport := binary.BigEndian.Uint16(udp[0:2]); sport < 0xc000 {
+		t.Errorf("unexpected source port: %d", sport)
+	}
+	if dport := binary.BigEndian.Uint16(udp[2:4]); dport != 5555 {
+		t.Errorf("unexpected destination port: %d", dport)
+	}
+	if l := binary.BigEndian.Uint16(udp[4:6]); int(l) != len(in)-ethHeaderLen+8 {
+		t.Errorf("unexpected UDP length: %d", l)
+	}
+	if !bytes.Equal(out[ethHeaderLen+encapHeaderLen:], in[ethHeaderLen:]) {
+		t.Error("the inner packet should be kept")
+	}
+}
@@ -1,10 +1,13 @@
 package founat
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 
+	"github.com/containernetworking/plugins/pkg/utils/sysctl"
 	"github.com/coreos/go-iptables/iptables"
 	"github.com/vishvananda/netlink"
 )
@@ -14,6 +17,10 @@ const (
 	egressProtocolID = 30
 	egressRulePrio   = 2000
 
+	egressUplinkTableID = 119
+	egressUplinkPrio    = 2100
+	egressUplinkMark    = 119
+
 	egressDummy = "egress-dummy"
 )
 
@@ -24,18 +31,66 @@ type Egress interface {
 	AddClient(net.IP, netlink.Link) error
 }
 
-// NewEgress creates an Egress
+// EgressOptions is a set of optional settings for Egress.
+type EgressOptions struct {
+	// SNAT makes Egress use iptables SNAT target instead of MASQUERADE.
+	//
+	// Unlike MASQUERADE, SNAT translates the source address to a fixed
+	// address of the outgoing interface, which is the pod address for the
+	// pod interface, without looking up the address for each connection.
+	// This is suitable for CNI plugins that route packets with eBPF such
+	// as Cilium.
+	SNAT bool
+
+	// GatewayV4 is the next hop for IPv4 packets from clients.
+	// If not nil, the packets are routed to the gateway through the interface
+	// instead of the default route of the pod.  This is used to send packets
+	// out of a secondary interface such as the one attached by Multus.
+	GatewayV4 net.IP
+
+	// GatewayV6 is the same as GatewayV4 for IPv6.
+	GatewayV6 net.IP
+
+	// NextHops are routes for packets from clients to specific destinations.
+	// They take precedence over GatewayV4 and GatewayV6.
+	NextHops []NextHop
+}
+
+// NextHop is a route to Destination via Gateway.
+type NextHop struct {
+	Destination *net.IPNet
+	Gateway     net.IP
+}
+
+// NewEgress creates an Egress that uses iptables MASQUERADE target.
 func NewEgress(iface string, ipv4, ipv6 net.IP) Egress {
+	return NewEgressWithOptions(iface, ipv4, ipv6, EgressOptions{})
+}
+
+// NewEgressWithOptions creates an Egress with optional settings.
+func NewEgressWithOptions(iface string, ipv4, ipv6 net.IP, opts EgressOptions) Egress {
 	if ipv4 != nil && ipv4.To4() == nil {
 		panic("invalid IPv4 address")
 	}
 	if ipv6 != nil && ipv6.To4() != nil {
 		panic("invalid IPv6 address")
 	}
+	if opts.GatewayV4 != nil && opts.GatewayV4.To4() == nil {
+		panic("invalid IPv4 gateway")
+	}
+	if opts.GatewayV6 != nil && opts.GatewayV6.To4() != nil {
+		panic("invalid IPv6 gateway")
+	}
+	for _, h := range opts.NextHops {
+		if (h.Destination.IP.To4() != nil) != (h.Gateway.To4() != nil) {
+			panic("IP family mismatch in next hop")
+		}
+	}
 	return &egress{
 		iface: iface,
 		ipv4:  ipv4,
 		ipv6:  ipv6,
+		opts:  opts,
 	}
 }
 
@@ -43,59 +98,272 @@ type egress struct {
 	iface string
 	ipv4  net.IP
 	ipv6  net.IP
+	opts  EgressOptions
 
 	mu sync.Mutex
 }
 
-func (e *egress) newRule(family int) *netlink.Rule {
+// DefaultInterface returns the name of the interface of the default route.
+// IPv4 default route takes precedence over IPv6 one.
+func DefaultInterface() (string, error) {
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		routes, err := netlink.RouteListFiltered(family, &netlink.Route{Table: mainTableID}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return "", fmt.Errorf("netlink: failed to list routes: %w", err)
+		}
+
+		var found *netlink.Route
+		for i, r := range routes {
+			if r.Dst != nil {
+				if ones, _ := r.Dst.Mask.Size(); ones != 0 {
+					continue
+				}
+			}
+			if found == nil || r.Priority < found.Priority {
+				found = &routes[i]
+			}
+		}
+		if found == nil {
+			continue
+		}
+
+		index := found.LinkIndex
+		if index == 0 && len(found.MultiPath) > 0 {
+			index = found.MultiPath[0].LinkIndex
+		}
+		link, err := netlink.LinkByIndex(index)
+		if err != nil {
+			return "", fmt.Errorf("netlink: failed to get link %d: %w", index, err)
+		}
+		return link.Attrs().Name, nil
+	}
+
+	return "", errors.New("no default route")
+}
+
+func (e *egress) newRule(family int, iface string) *netlink.Rule {
 	r := netlink.NewRule()
 	r.Family = family
-	r.IifName = e.iface
+	r.IifName = iface
 	r.Table = egressTableID
 	r.Priority = egressRulePrio
 	return r
 }
 
-func (e *egress) Init() error {
-	// avoid double initialization in case the program restarts
-	_, err := netlink.LinkByName(egressDummy)
-	if err == nil {
-		return nil
+// natRule returns the rule to translate the source address of packets
+// from clients going out of `iface`.  `addr` is the pod address.
+func (e *egress) natRule(family int, addr net.IP, iface string) ([]string, error) {
+	ipn := netlink.NewIPNet(addr)
+	if !e.opts.SNAT {
+		return []string{"!", "-s", ipn.String(), "-o", iface, "-j", "MASQUERADE"}, nil
 	}
-	if _, ok := err.(netlink.LinkNotFoundError); !ok {
-		return err
+
+	src, err := interfaceAddress(family, iface, addr)
+	if err != nil {
+		return nil, err
 	}
+	return []string{"!", "-s", ipn.String(), "-o", iface, "-j", "SNAT", "--to-source", src.String()}, nil
+}
 
-	if e.ipv4 != nil {
-		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+// interfaceAddress returns an address of `iface` to be the source address
+// of packets going out of it.  Replies to packets from other addresses
+// would not come back through `iface`.  `addr` is returned if `iface` has
+// it, so the pod address is used for the pod interface.
+func interfaceAddress(family int, iface string, addr net.IP) (net.IP, error) {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: failed to get link %s: %w", iface, err)
+	}
+	addrs, err := netlink.AddrList(link, family)
+	if err != nil {
+		return nil, fmt.Errorf("netlink: failed to list addresses of %s: %w", iface, err)
+	}
+
+	var found net.IP
+	for _, a := range addrs {
+		if a.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if a.IP.Equal(addr) {
+			return addr, nil
+		}
+		if found == nil {
+			found = a.IP
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no address to translate packets going out of %s", iface)
+	}
+	return found, nil
+}
+
+// nextHops returns the next hops for the IP family.
+func (e *egress) nextHops(family int) []NextHop {
+	var hops []NextHop
+	for _, h := range e.opts.NextHops {
+		if (h.Gateway.To4() != nil) == (family == netlink.FAMILY_V4) {
+			hops = append(hops, h)
+		}
+	}
+	return hops
+}
+
+// outInterfaces returns the names of the interfaces through which packets
+// from clients go out.  They are the interface of Egress and the ones
+// to reach the next hops.
+func (e *egress) outInterfaces(hops []NextHop) ([]string, error) {
+	ifaces := []string{e.iface}
+	seen := map[string]bool{e.iface: true}
+	for _, h := range hops {
+		routes, err := netlink.RouteGet(h.Gateway)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("netlink: failed to get route to %s: %w", h.Gateway.String(), err)
+		}
+		if len(routes) == 0 {
+			return nil, fmt.Errorf("no route to %s", h.Gateway.String())
 		}
-		ipn := netlink.NewIPNet(e.ipv4)
-		err = ipt.Append("nat", "POSTROUTING", "!", "-s", ipn.String(), "-o", e.iface, "-j", "MASQUERADE")
+		link, err := netlink.LinkByIndex(routes[0].LinkIndex)
 		if err != nil {
-			return fmt.Errorf("failed to setup masquerade rule for IPv4: %w", err)
+			return nil, fmt.Errorf("netlink: failed to get link %d: %w", routes[0].LinkIndex, err)
 		}
+		name := link.Attrs().Name
+		if !seen[name] {
+			seen[name] = true
+			ifaces = append(ifaces, name)
+		}
+	}
+	return ifaces, nil
+}
 
-		rule := e.newRule(netlink.FAMILY_V4)
-		if err := netlink.RuleAdd(rule); err != nil {
-			return fmt.Errorf("netlink: failed to add egress rule for IPv4: %w", err)
+// setupUplink routes packets from FoU tunnels to the gateway and next hops.
+//
+// Packets decapsulated by FoU tunnel devices are marked in mangle table
+// and routed by the rule for the mark to table 119, which has the default
+// route via the gateway and the routes to the next hops.
+func (e *egress) setupUplink(family int, gw net.IP, hops []NextHop, ifaces []string) error {
+	proto := iptables.ProtocolIPv4
+	prefix := FoU4LinkPrefix
+	defaultGW := &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+	if family == netlink.FAMILY_V6 {
+		proto = iptables.ProtocolIPv6
+		prefix = FoU6LinkPrefix
+		defaultGW = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+	} else {
+		// returning packets would fail the reverse path check because
+		// the main table does not route them to the interfaces.
+		for _, iface := range ifaces {
+			if _, err := sysctl.Sysctl("net.ipv4.conf."+iface+".rp_filter", "0"); err != nil {
+				return fmt.Errorf("setting net.ipv4.conf.%s.rp_filter=0 failed: %w", iface, err)
+			}
 		}
 	}
-	if e.ipv6 != nil {
-		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+
+	if gw != nil {
+		link, err := netlink.LinkByName(e.iface)
+		if err != nil {
+			return fmt.Errorf("netlink: failed to get link %s: %w", e.iface, err)
+		}
+		err = netlink.RouteReplace(&netlink.Route{
+			Dst:       defaultGW,
+			Gw:        gw,
+			LinkIndex: link.Attrs().Index,
+			Table:     egressUplinkTableID,
+			Protocol:  egressProtocolID,
+		})
+		if err != nil {
+			return fmt.Errorf("netlink: failed to add default route via %s to table %d: %w", gw.String(), egressUplinkTableID, err)
+		}
+	}
+
+	for _, h := range hops {
+		err := netlink.RouteReplace(&netlink.Route{
+			Dst:      h.Destination,
+			Gw:       h.Gateway,
+			Table:    egressUplinkTableID,
+			Protocol: egressProtocolID,
+		})
+		if err != nil {
+			return fmt.Errorf("netlink: failed to add route to %s via %s to table %d: %w",
+				h.Destination.String(), h.Gateway.String(), egressUplinkTableID, err)
+		}
+	}
+
+	ipt, err := iptables.NewWithProtocol(proto)
+	if err != nil {
+		return err
+	}
+	err = ipt.AppendUnique("mangle", "PREROUTING", "-i", prefix+"+", "-j", "MARK", "--set-mark", strconv.Itoa(egressUplinkMark))
+	if err != nil {
+		return fmt.Errorf("failed to setup mark rule: %w", err)
+	}
+
+	r := netlink.NewRule()
+	r.Family = family
+	r.Mark = egressUplinkMark
+	r.Table = egressUplinkTableID
+	r.Priority = egressUplinkPrio
+	if err := netlink.RuleAdd(r); err != nil {
+		return fmt.Errorf("netlink: failed to add uplink rule: %w", err)
+	}
+	return nil
+}
+
+func (e *egress) init(family int, addr, gw net.IP) error {
+	proto := iptables.ProtocolIPv4
+	if family == netlink.FAMILY_V6 {
+		proto = iptables.ProtocolIPv6
+	}
+	ipt, err := iptables.NewWithProtocol(proto)
+	if err != nil {
+		return err
+	}
+
+	hops := e.nextHops(family)
+	ifaces, err := e.outInterfaces(hops)
+	if err != nil {
+		return err
+	}
+
+	for _, iface := range ifaces {
+		natRule, err := e.natRule(family, addr, iface)
 		if err != nil {
 			return err
 		}
-		ipn := netlink.NewIPNet(e.ipv6)
-		err = ipt.Append("nat", "POSTROUTING", "!", "-s", ipn.String(), "-o", e.iface, "-j", "MASQUERADE")
+		err = ipt.Append("nat", "POSTROUTING", natRule...)
 		if err != nil {
-			return fmt.Errorf("failed to setup masquerade rule for IPv6: %w", err)
+			return fmt.Errorf("failed to setup NAT rule for %s: %w", iface, err)
 		}
 
-		rule := e.newRule(netlink.FAMILY_V6)
+		rule := e.newRule(family, iface)
 		if err := netlink.RuleAdd(rule); err != nil {
-			return fmt.Errorf("netlink: failed to add egress rule for IPv6: %w", err)
+			return fmt.Errorf("netlink: failed to add egress rule for %s: %w", iface, err)
+		}
+	}
+
+	if gw == nil && len(hops) == 0 {
+		return nil
+	}
+	return e.setupUplink(family, gw, hops, ifaces)
+}
+
+func (e *egress) Init() error {
+	// avoid double initialization in case the program restarts
+	_, err := netlink.LinkByName(egressDummy)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(netlink.LinkNotFoundError); !ok {
+		return err
+	}
+
+	if e.ipv4 != nil {
+		if err := e.init(netlink.FAMILY_V4, e.ipv4, e.opts.GatewayV4); err != nil {
+			return fmt.Errorf("failed to initialize egress for IPv4: %w", err)
+		}
+	}
+	if e.ipv6 != nil {
+		if err := e.init(netlink.FAMILY_V6, e.ipv6, e.opts.GatewayV6); err != nil {
+			return fmt.Errorf("failed to initialize egress for IPv6: %w", err)
 		}
 	}
 
@@ -15,6 +15,11 @@ func TestEgress(t *testing.T) {
 	t.Run("Dual", testEgressDual)
 	t.Run("IPv4", testEgressV4)
 	t.Run("IPv6", testEgressV6)
+	t.Run("SNAT", testEgressSNAT)
+	t.Run("DefaultInterface", testDefaultInterface)
+	t.Run("Uplink", testEgressUplink)
+	t.Run("SecondarySNAT", testEgressSecondarySNAT)
+	t.Run("NextHopSNAT", testEgressNextHopSNAT)
 }
 
 func testEgressDual(t *testing.T) {
@@ -316,3 +321,331 @@ func testEgressV6(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func testEgressSNAT(t *testing.T) {
+	t.Parallel()
+
+	eNS, err := ns.GetNS("/run/netns/test-egress-snat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eNS.Close()
+
+	err = eNS.Do(func(ns.NetNS) error {
+		eg := NewEgressWithOptions("lo", net.ParseIP("127.0.0.1"), net.ParseIP("::1"), EgressOptions{SNAT: true})
+		if err := eg.Init(); err != nil {
+			return fmt.Errorf("eg.Init failed: %w", err)
+		}
+
+		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+		if err != nil {
+			return err
+		}
+		exist, err := ipt.Exists("nat", "POSTROUTING", "!", "-s", "127.0.0.1/32", "-o", "lo", "-j", "SNAT", "--to-source", "127.0.0.1")
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New("SNAT rule not found for IPv4")
+		}
+
+		ipt, err = iptables.NewWithProtocol(iptables.ProtocolIPv6)
+		if err != nil {
+			return err
+		}
+		exist, err = ipt.Exists("nat", "POSTROUTING", "!", "-s", "::1/128", "-o", "lo", "-j", "SNAT", "--to-source", "::1")
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New("SNAT rule not found for IPv6")
+		}
+
+		exist, err = ipt.Exists("nat", "POSTROUTING", "!", "-s", "::1/128", "-o", "lo", "-j", "MASQUERADE")
+		if err != nil {
+			return err
+		}
+		if exist {
+			return errors.New("MASQUERADE rule should not exist")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func testDefaultInterface(t *testing.T) {
+	t.Parallel()
+
+	eNS, err := ns.GetNS("/run/netns/test-egress-iface")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eNS.Close()
+
+	err = eNS.Do(func(ns.NetNS) error {
+		if _, err := DefaultInterface(); err == nil {
+			return errors.New("DefaultInterface should fail without default route")
+		}
+
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = "net1"
+		attrs.Flags = net.FlagUp
+		if err := netlink.LinkAdd(&netlink.Dummy{LinkAttrs: attrs}); err != nil {
+			return err
+		}
+		link, err := netlink.LinkByName("net1")
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+		err = netlink.RouteAdd(&netlink.Route{
+			Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			LinkIndex: link.Attrs().Index,
+			Scope:     netlink.SCOPE_LINK,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add default route: %w", err)
+		}
+
+		iface, err := DefaultInterface()
+		if err != nil {
+			return err
+		}
+		if iface != "net1" {
+			return fmt.Errorf("unexpected default interface: %s", iface)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func testEgressUplink(t *testing.T) {
+	t.Parallel()
+
+	eNS, err := ns.GetNS("/run/netns/test-egress-uplink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eNS.Close()
+
+	err = eNS.Do(func(ns.NetNS) error {
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = "net1"
+		if err := netlink.LinkAdd(&netlink.Dummy{LinkAttrs: attrs}); err != nil {
+			return err
+		}
+		link, err := netlink.LinkByName("net1")
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+		addr, _ := netlink.ParseAddr("192.168.100.10/24")
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return err
+		}
+
+		_, dst, _ := net.ParseCIDR("203.0.113.0/24")
+		eg := NewEgressWithOptions("net1", net.ParseIP("192.168.100.10"), nil, EgressOptions{
+			GatewayV4: net.ParseIP("192.168.100.1"),
+			NextHops: []NextHop{
+				{Destination: dst, Gateway: net.ParseIP("192.168.100.2")},
+			},
+		})
+		if err := eg.Init(); err != nil {
+			return fmt.Errorf("eg.Init failed: %w", err)
+		}
+
+		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+		if err != nil {
+			return err
+		}
+		exist, err := ipt.Exists("mangle", "PREROUTING", "-i", "fou4_+", "-j", "MARK", "--set-mark", "119")
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New("mark rule not found")
+		}
+
+		rm, err := ruleMap(netlink.FAMILY_V4)
+		if err != nil {
+			return err
+		}
+		if r, ok := rm[2100]; !ok {
+			return errors.New("no ip rule 2100 for IPv4")
+		} else {
+			if r.Table != 119 {
+				return fmt.Errorf("wrong table for uplink rule: %d", r.Table)
+			}
+			if r.Mark != 119 {
+				return fmt.Errorf("wrong mark for uplink rule: %d", r.Mark)
+			}
+		}
+
+		routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: 119}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return err
+		}
+		if len(routes) != 2 {
+			return fmt.Errorf("wrong number of routes in table 119: %d", len(routes))
+		}
+		for _, r := range routes {
+			if r.LinkIndex != link.Attrs().Index {
+				return fmt.Errorf("wrong link for uplink route: %d", r.LinkIndex)
+			}
+			if r.Dst == nil || r.Dst.IP.IsUnspecified() {
+				if !r.Gw.Equal(net.ParseIP("192.168.100.1")) {
+					return fmt.Errorf("wrong default gateway: %s", r.Gw)
+				}
+				continue
+			}
+			if r.Dst.String() != "203.0.113.0/24" || !r.Gw.Equal(net.ParseIP("192.168.100.2")) {
+				return fmt.Errorf("wrong next hop route: %s via %s", r.Dst, r.Gw)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// addTestLink adds a dummy link having `addr` in the current network namespace.
+func addTestLink(name, addr string) (netlink.Link, error) {
+	attrs := netlink.NewLinkAttrs()
+	attrs.Name = name
+	if err := netlink.LinkAdd(&netlink.Dummy{LinkAttrs: attrs}); err != nil {
+		return nil, err
+	}
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return nil, err
+	}
+	a, err := netlink.ParseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := netlink.AddrAdd(link, a); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func testEgressSecondarySNAT(t *testing.T) {
+	t.Parallel()
+
+	eNS, err := ns.GetNS("/run/netns/test-egress-secondary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eNS.Close()
+
+	err = eNS.Do(func(ns.NetNS) error {
+		if _, err := addTestLink("eth0", "10.1.1.5/24"); err != nil {
+			return err
+		}
+		if _, err := addTestLink("net1", "192.168.100.10/24"); err != nil {
+			return err
+		}
+
+		// packets going out of the secondary interface are translated
+		// to its own address, not to the pod address.
+		eg := NewEgressWithOptions("net1", net.ParseIP("10.1.1.5"), nil, EgressOptions{
+			SNAT:      true,
+			GatewayV4: net.ParseIP("192.168.100.1"),
+		})
+		if err := eg.Init(); err != nil {
+			return fmt.Errorf("eg.Init failed: %w", err)
+		}
+
+		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+		if err != nil {
+			return err
+		}
+		exist, err := ipt.Exists("nat", "POSTROUTING", "!", "-s", "10.1.1.5/32", "-o", "net1", "-j", "SNAT", "--to-source", "192.168.100.10")
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New("SNAT rule to the address of net1 not found")
+		}
+		exist, err = ipt.Exists("nat", "POSTROUTING", "!", "-s", "10.1.1.5/32", "-o", "net1", "-j", "SNAT", "--to-source", "10.1.1.5")
+		if err != nil {
+			return err
+		}
+		if exist {
+			return errors.New("SNAT rule to the pod address should not exist for net1")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func testEgressNextHopSNAT(t *testing.T) {
+	t.Parallel()
+
+	eNS, err := ns.GetNS("/run/netns/test-egress-nexthop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eNS.Close()
+
+	err = eNS.Do(func(ns.NetNS) error {
+		if _, err := addTestLink("eth0", "10.1.1.5/24"); err != nil {
+			return err
+		}
+		if _, err := addTestLink("net1", "192.168.100.10/24"); err != nil {
+			return err
+		}
+
+		// each interface to reach next hops has its own SNAT rule.
+		_, dst, _ := net.ParseCIDR("203.0.113.0/24")
+		eg := NewEgressWithOptions("eth0", net.ParseIP("10.1.1.5"), nil, EgressOptions{
+			SNAT: true,
+			NextHops: []NextHop{
+				{Destination: dst, Gateway: net.ParseIP("192.168.100.1")},
+			},
+		})
+		if err := eg.Init(); err != nil {
+			return fmt.Errorf("eg.Init failed: %w", err)
+		}
+
+		ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+		if err != nil {
+			return err
+		}
+		rules := []struct {
+			iface string
+			to    string
+		}{
+			{"eth0", "10.1.1.5"},
+			{"net1", "192.168.100.10"},
+		}
+		for _, r := range rules {
+			exist, err := ipt.Exists("nat", "POSTROUTING", "!", "-s", "10.1.1.5/32", "-o", r.iface, "-j", "SNAT", "--to-source", r.to)
+			if err != nil {
+				return err
+			}
+			if !exist {
+				return fmt.Errorf("SNAT rule to %s not found for %s", r.to, r.iface)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
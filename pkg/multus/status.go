@@ -0,0 +1,86 @@
+package multus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Annotation keys of Multus
+const (
+	AnnNetworks      = "k8s.v1.cni.cncf.io/networks"
+	AnnNetworkStatus = "k8s.v1.cni.cncf.io/network-status"
+)
+
+// NetworkStatus is an element of the network-status annotation.
+// Ref. https://github.com/k8snetworkplumbingwg/multi-net-spec
+type NetworkStatus struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	Mac       string   `json:"mac,omitempty"`
+	Default   bool     `json:"default,omitempty"`
+	Gateway   []string `json:"gateway,omitempty"`
+}
+
+// Gateways returns IPv4 and IPv6 gateway addresses of the network.
+// Either or both of them can be nil.
+func (s *NetworkStatus) Gateways() (ipv4, ipv6 net.IP, err error) {
+	for _, gw := range s.Gateway {
+		ip := net.ParseIP(gw)
+		if ip == nil {
+			return nil, nil, fmt.Errorf("invalid gateway address in network %s: %s", s.Name, gw)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			if ipv4 == nil {
+				ipv4 = ip4
+			}
+		} else if ipv6 == nil {
+			ipv6 = ip
+		}
+	}
+	return ipv4, ipv6, nil
+}
+
+// QualifiedName returns `ref` in "namespace/name" format.
+// `ref` is a reference to NetworkAttachmentDefinition in "name" or
+// "namespace/name" format.  `namespace` is used if `ref` has no namespace.
+func QualifiedName(namespace, ref string) string {
+	if strings.Contains(ref, "/") {
+		return ref
+	}
+	return namespace + "/" + ref
+}
+
+// NetworksAnnotation returns the value of the networks annotation
+// that attaches the network `ref` as `iface`.  `iface` can be empty.
+func NetworksAnnotation(ref, iface string) string {
+	if iface == "" {
+		return ref
+	}
+	return ref + "@" + iface
+}
+
+// FindNetworkStatus returns the status of the network `ref` attached to `pod`.
+func FindNetworkStatus(pod *corev1.Pod, ref string) (*NetworkStatus, error) {
+	data, ok := pod.Annotations[AnnNetworkStatus]
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s has no %s annotation", pod.Namespace, pod.Name, AnnNetworkStatus)
+	}
+
+	var statuses []NetworkStatus
+	if err := json.Unmarshal([]byte(data), &statuses); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", AnnNetworkStatus, err)
+	}
+
+	name := QualifiedName(pod.Namespace, ref)
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i], nil
+		}
+	}
+	return nil, fmt.Errorf("network %s is not attached to pod %s/%s", name, pod.Namespace, pod.Name)
+}
@@ -0,0 +1,93 @@
+package multus
+
+import (
+	"net"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testNetworkStatus = `[{
+    "name": "cilium",
+    "interface": "eth0",
+    "ips": ["10.64.0.10"],
+    "default": true
+},{
+    "name": "egress/uplink",
+    "interface": "net1",
+    "ips": ["192.168.100.10", "fd10::10"],
+    "mac": "86:1d:96:ff:55:0d",
+    "gateway": ["192.168.100.1", "fd10::1"]
+}]`
+
+func TestFindNetworkStatus(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "egress",
+			Name:      "pod1",
+			Annotations: map[string]string{
+				AnnNetworkStatus: testNetworkStatus,
+			},
+		},
+	}
+
+	for _, ref := range []string{"uplink", "egress/uplink"} {
+		st, err := FindNetworkStatus(pod, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Interface != "net1" {
+			t.Errorf("unexpected interface: %s", st.Interface)
+		}
+		ipv4, ipv6, err := st.Gateways()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ipv4.Equal(net.ParseIP("192.168.100.1")) {
+			t.Errorf("unexpected IPv4 gateway: %s", ipv4)
+		}
+		if !ipv6.Equal(net.ParseIP("fd10::1")) {
+			t.Errorf("unexpected IPv6 gateway: %s", ipv6)
+		}
+	}
+
+	if _, err := FindNetworkStatus(pod, "other/uplink"); err == nil {
+		t.Error("network in another namespace should not be found")
+	}
+
+	pod.Annotations[AnnNetworkStatus] = "{"
+	if _, err := FindNetworkStatus(pod, "uplink"); err == nil {
+		t.Error("broken annotation should be rejected")
+	}
+
+	delete(pod.Annotations, AnnNetworkStatus)
+	if _, err := FindNetworkStatus(pod, "uplink"); err == nil {
+		t.Error("missing annotation should be rejected")
+	}
+}
+
+func TestGateways(t *testing.T) {
+	st := &NetworkStatus{Name: "a", Gateway: []string{"fd10::1"}}
+	ipv4, ipv6, err := st.Gateways()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ipv4 != nil || !ipv6.Equal(net.ParseIP("fd10::1")) {
+		t.Errorf("unexpected gateways: %s, %s", ipv4, ipv6)
+	}
+
+	st.Gateway = []string{"a.b.c.d"}
+	if _, _, err := st.Gateways(); err == nil {
+		t.Error("invalid address should be rejected")
+	}
+}
+
+func TestNetworksAnnotation(t *testing.T) {
+	if v := NetworksAnnotation("ns/uplink", "net1"); v != "ns/uplink@net1" {
+		t.Errorf("unexpected value: %s", v)
+	}
+	if v := NetworksAnnotation("uplink", ""); v != "uplink" {
+		t.Errorf("unexpected value: %s", v)
+	}
+}
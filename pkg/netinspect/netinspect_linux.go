@@ -0,0 +1,187 @@
+package netinspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"syscall"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// FindNetNS returns the path of the network namespace that has `ip`.
+// It looks up namespaces of processes under `procDir`, so it needs to see
+// the host's PID namespace to find namespaces of pods.
+func FindNetNS(procDir string, ip net.IP) (string, error) {
+	paths, err := filepath.Glob(filepath.Join(procDir, "[0-9]*", "ns", "net"))
+	if err != nil {
+		return "", err
+	}
+
+	seen := make(map[uint64]bool)
+	for _, p := range paths {
+		var st syscall.Stat_t
+		if err := syscall.Stat(p, &st); err != nil {
+			// the process may have exited.
+			continue
+		}
+		if seen[st.Ino] {
+			continue
+		}
+		seen[st.Ino] = true
+
+		found := false
+		err := ns.WithNetNSPath(p, func(_ ns.NetNS) error {
+			addrs, err := netlink.AddrList(nil, netlink.FAMILY_ALL)
+			if err != nil {
+				return err
+			}
+			for _, a := range addrs {
+				if a.IP.Equal(ip) {
+					found = true
+					return nil
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			continue
+		}
+		if found {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrNotFound, ip)
+}
+
+// Dump reads links, routes of all tables, and rules in the network namespace.
+func Dump(nsPath string) (*State, error) {
+	st := &State{}
+	err := ns.WithNetNSPath(nsPath, func(_ ns.NetNS) error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("netlink: failed to list links: %w", err)
+		}
+		names := make(map[int]string)
+		for _, l := range links {
+			attrs := l.Attrs()
+			names[attrs.Index] = attrs.Name
+			link := Link{
+				Name:  attrs.Name,
+				Index: attrs.Index,
+				Type:  l.Type(),
+				Up:    attrs.Flags&net.FlagUp != 0,
+			}
+			switch t := l.(type) {
+			case *netlink.Iptun:
+				link.Remote = ipString(t.Remote)
+				link.EncapDport = int(t.EncapDport)
+			case *netlink.Ip6tnl:
+				link.Remote = ipString(t.Remote)
+				link.EncapDport = int(t.EncapDport)
+			}
+			st.Links = append(st.Links, link)
+		}
+
+		routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+		if err != nil {
+			return fmt.Errorf("netlink: failed to list routes: %w", err)
+		}
+		for _, r := range routes {
+			dst := "default"
+			if r.Dst != nil {
+				dst = r.Dst.String()
+			}
+			st.Routes = append(st.Routes, Route{
+				Table:    r.Table,
+				Dst:      dst,
+				Gateway:  ipString(r.Gw),
+				Dev:      names[r.LinkIndex],
+				Protocol: int(r.Protocol),
+			})
+		}
+
+		rules, err := netlink.RuleList(netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("netlink: failed to list rules: %w", err)
+		}
+		for _, r := range rules {
+			rule := Rule{
+				Priority: r.Priority,
+				Table:    r.Table,
+				Mark:     r.Mark,
+			}
+			if r.Src != nil {
+				rule.Src = r.Src.String()
+			}
+			if r.Dst != nil {
+				rule.Dst = r.Dst.String()
+			}
+			st.Rules = append(st.Rules, rule)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Get sends an HTTP GET request to `url` from the network namespace
+// and returns the response body.
+func Get(ctx context.Context, nsPath, url string) ([]byte, error) {
+	netNS, err := ns.GetNS(nsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer netNS.Close()
+
+	dialer := &net.Dialer{}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: nil,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				var conn net.Conn
+				// the socket belongs to the network namespace where it is created.
+				err := netNS.Do(func(_ ns.NetNS) error {
+					var err error
+					conn, err = dialer.DialContext(ctx, network, addr)
+					return err
+				})
+				return conn, err
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+func ipString(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
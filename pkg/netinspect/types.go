@@ -0,0 +1,45 @@
+// Package netinspect reads the network state of pod network namespaces
+// for debugging egress.
+package netinspect
+
+import "errors"
+
+// ErrNotFound is returned when no network namespace has the address.
+var ErrNotFound = errors.New("network namespace not found")
+
+// State is a snapshot of the network state in a network namespace.
+type State struct {
+	Links  []Link  `json:"links"`
+	Routes []Route `json:"routes"`
+	Rules  []Rule  `json:"rules"`
+}
+
+// Link is a network interface.
+type Link struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	Up    bool   `json:"up"`
+
+	// Remote and EncapDport are set for FoU tunnel links.
+	Remote     string `json:"remote,omitempty"`
+	EncapDport int    `json:"encapDport,omitempty"`
+}
+
+// Route is a routing table entry.
+type Route struct {
+	Table    int    `json:"table"`
+	Dst      string `json:"dst"`
+	Gateway  string `json:"gateway,omitempty"`
+	Dev      string `json:"dev,omitempty"`
+	Protocol int    `json:"protocol"`
+}
+
+// Rule is a policy routing rule.
+type Rule struct {
+	Priority int    `json:"priority"`
+	Table    int    `json:"table"`
+	Src      string `json:"src,omitempty"`
+	Dst      string `json:"dst,omitempty"`
+	Mark     uint32 `json:"mark,omitempty"`
+}
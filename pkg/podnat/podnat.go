@@ -0,0 +1,453 @@
+// Package podnat sets up egress NAT in network namespaces of client pods.
+// It is shared by egress-gw-agent and the standalone mode of the CNI plugin.
+package podnat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/plugins/pkg/ns"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/founat"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewError returns a gRPC status error having CNIError as its detail.
+func NewError(c codes.Code, cniCode cnirpc.ErrorCode, msg, details string) error {
+	st := status.New(c, msg)
+	st, err := st.WithDetails(&cnirpc.CNIError{Code: cniCode, Msg: msg, Details: details})
+	if err != nil {
+		panic(err)
+	}
+
+	return st.Err()
+}
+
+// NewInternalError returns an internal error caused by err.
+func NewInternalError(err error, msg string) error {
+	return NewError(codes.Internal, cnirpc.ErrorCode_INTERNAL, msg, err.Error())
+}
+
+// PluginConf is the network configuration given to the CNI plugin.
+type PluginConf struct {
+	types.NetConf
+
+	// These are fields parsed out of the config or the environment;
+	// included here for convenience
+	ContainerID string    `json:"-"`
+	ContIPv4    net.IPNet `json:"-"`
+	ContIPv6    net.IPNet `json:"-"`
+}
+
+// ParseConfig parses the supplied configuration (and prevResult) from stdin.
+func ParseConfig(stdin []byte, ifName string) (*PluginConf, *current.Result, error) {
+	conf := PluginConf{}
+
+	if err := json.Unmarshal(stdin, &conf); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse network configuration: %v", err)
+	}
+
+	// Parse previous result.
+	var result *current.Result
+	if conf.RawPrevResult != nil {
+		var err error
+		if err = cnirpc.ParsePrevResult(&conf.NetConf); err != nil {
+			return nil, nil, fmt.Errorf("could not parse prevResult: %v", err)
+		}
+
+		result, err = current.NewResultFromResult(conf.PrevResult)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not convert result to current version: %v", err)
+		}
+	}
+
+	if result != nil {
+		conf.ContIPv4, conf.ContIPv6 = containerIPs(result, ifName)
+	}
+
+	return &conf, result, nil
+}
+
+// containerIPs returns the IPv4 and IPv6 addresses of the container
+// interface `ifName` in `result`.
+//
+// Primary plugins report the interface of an address differently.
+// Cilium and the bridge plugin, which Flannel delegates to, refer to the
+// interface in the sandbox.  Others such as Calico refer to the host-side
+// veth or to no interface.  Addresses of the sandbox interface are
+// preferred, and the others are used only if it has none.
+func containerIPs(result *current.Result, ifName string) (ipv4, ipv6 net.IPNet) {
+	var hostIPv4, hostIPv6 net.IPNet
+	for _, ip := range result.IPs {
+		inSandbox := true
+		if ip.Interface != nil {
+			intIdx := *ip.Interface
+			if intIdx >= 0 && intIdx < len(result.Interfaces) {
+				iface := result.Interfaces[intIdx]
+				if iface.Sandbox != "" && iface.Name != ifName {
+					// another interface of the container
+					continue
+				}
+				inSandbox = iface.Sandbox != ""
+			}
+		}
+
+		dst4, dst6 := &ipv4, &ipv6
+		if !inSandbox {
+			dst4, dst6 = &hostIPv4, &hostIPv6
+		}
+		if ip.Address.IP.To4() != nil {
+			if dst4.IP == nil {
+				*dst4 = ip.Address
+			}
+		} else if dst6.IP == nil {
+			*dst6 = ip.Address
+		}
+	}
+
+	if ipv4.IP == nil {
+		ipv4 = hostIPv4
+	}
+	if ipv6.IP == nil {
+		ipv6 = hostIPv6
+	}
+	return ipv4, ipv6
+}
+
+// Options are options to set up egress NAT.
+type Options struct {
+	// EgressPort is the UDP port number of FoU tunnels.
+	EgressPort int
+
+	// BPFDatapath enables the eBPF datapath to steer IPv4 egress traffic.
+	BPFDatapath bool
+
+	// BPFPinDir is the directory in bpffs to pin maps of the eBPF datapath.
+	// If empty, maps are not pinned and cannot be updated by Update.
+	BPFPinDir string
+}
+
+// BPFPinPath returns the path to pin the map of the container.
+// It returns an empty string if BPFPinDir is empty.
+func (o Options) BPFPinPath(containerID string) string {
+	if o.BPFPinDir == "" {
+		return ""
+	}
+	return filepath.Join(o.BPFPinDir, containerID)
+}
+
+// GWNets is a gateway and the destination networks routed to it.
+type GWNets struct {
+	Gateway  net.IP
+	Networks []*net.IPNet
+}
+
+// Add sets up egress NAT for the pod of ADD command `args`.
+//
+// It returns the result of ADD serialized into JSON, and the gateways
+// set up for the pod.  The gateways are nil if the pod uses no egress.
+// Errors are gRPC status errors created by NewError.
+func Add(ctx context.Context, r client.Reader, args *cnirpc.CNIArgs, opts Options, logger *zap.Logger) ([]byte, []GWNets, error) {
+	podName := args.Args[constants.PodNameKey]
+	podNS := args.Args[constants.PodNamespaceKey]
+	if podName == "" || podNS == "" {
+		logger.Sugar().Errorw("missing pod name/namespace", "args", args.Args)
+		return nil, nil, NewError(codes.InvalidArgument, cnirpc.ErrorCode_INVALID_ENVIRONMENT_VARIABLES,
+			"missing pod name/namespace", fmt.Sprintf("%+v", args.Args))
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: podNS, Name: podName}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Sugar().Errorw("pod not found", "name", podName, "namespace", podNS)
+			return nil, nil, NewError(codes.NotFound, cnirpc.ErrorCode_UNKNOWN_CONTAINER, "pod not found", err.Error())
+		}
+		logger.Sugar().Errorw("failed to get pod", "name", podName, "namespace", podNS, "error", err)
+		return nil, nil, NewInternalError(err, "failed to get pod")
+	}
+
+	g, err := GetGWNets(ctx, r, pod)
+	if err != nil {
+		logger.Sugar().Errorw("failed to get egress GW", "error", err)
+		return nil, nil, NewInternalError(err, "failed to get egress GW")
+	}
+
+	n, prevRes, err := ParseConfig(args.StdinData, args.Ifname)
+	if err != nil {
+		return nil, nil, NewError(codes.InvalidArgument, cnirpc.ErrorCode_DECODING_FAILURE,
+			"unable to parse CNI configuration", fmt.Sprintf("%+v", args.Args))
+	}
+
+	if g != nil && n.ContIPv4.IP == nil && n.ContIPv6.IP == nil {
+		logger.Sugar().Errorw("no container address in prevResult", "ifname", args.Ifname)
+		return nil, nil, NewError(codes.InvalidArgument, cnirpc.ErrorCode_INVALID_NETWORK_CONFIG,
+			"no container address in prevResult", args.Ifname)
+	}
+
+	if g != nil {
+		logger.Sugar().Info("enabling egress GW")
+		err = ns.WithNetNSPath(args.Netns, func(_ ns.NetNS) error {
+			if err := setupEgress(args.Ifname, n.ContIPv4.IP, n.ContIPv6.IP, g, opts.BPFPinPath(args.ContainerId), opts, logger); err != nil {
+				return err
+			}
+			return nil
+		})
+
+		if err != nil {
+			logger.Sugar().Errorw("failed to setup egress GW", "error", err)
+			return nil, nil, NewInternalError(err, "failed to setup egress GW")
+		}
+	}
+
+	data, err := json.Marshal(prevRes)
+	if err != nil {
+		logger.Sugar().Errorw("failed to marshal the result", "error", err)
+		return nil, nil, NewInternalError(err, "failed to marshal the result")
+	}
+	return data, g, nil
+}
+
+// setupEgress sets up FoU tunnels and routing to gateways in the current
+// network namespace.
+func setupEgress(ifname string, ipv4, ipv6 net.IP, l []GWNets, pinPath string, opts Options, log *zap.Logger) error {
+	ft := founat.NewFoUTunnel(0, opts.EgressPort, ipv4, ipv6)
+	if err := ft.Init(); err != nil {
+		return err
+	}
+
+	var bc founat.BPFClient
+	if opts.BPFDatapath && ipv4 != nil {
+		bc = founat.NewBPFClient(ifname, opts.EgressPort, ipv4, nil, pinPath)
+		if err := bc.Init(); err != nil {
+			// fall back to policy routing
+			log.Sugar().Warnw("failed to initialize eBPF datapath", "error", err)
+			bc = nil
+		} else {
+			defer bc.Close()
+		}
+	}
+
+	clientIPv4 := ipv4
+	if bc != nil {
+		clientIPv4 = nil
+	}
+	cl := founat.NewNatClient(clientIPv4, ipv6, nil)
+	if err := cl.Init(); err != nil {
+		return err
+	}
+
+	for _, gwn := range l {
+		if bc != nil && gwn.Gateway.To4() != nil {
+			if err := bc.AddEgress(gwn.Gateway, gwn.Networks); err != nil {
+				return err
+			}
+			continue
+		}
+
+		link, err := ft.AddPeer(gwn.Gateway)
+		if errors.Is(err, founat.ErrIPFamilyMismatch) {
+			// ignore unsupported IP family link
+			log.Sugar().Infow("ignored unsupported gateway", "gw", gwn.Gateway)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := cl.AddEgress(link, gwn.Networks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ErrNotPinned is returned by Update if the pod does not have a pinned map
+// of the eBPF datapath.
+var ErrNotPinned = errors.New("no pinned map of the eBPF datapath")
+
+// Update changes the destinations of the eBPF datapath of the container set
+// up by Add from `prev` to `l`.  `prev` is the gateways returned by Add or
+// given to the last Update.  `ipv4` is the IPv4 address of the pod.
+//
+// Only IPv4 gateways steered by the eBPF datapath are updated.  It returns
+// ErrNotPinned if the container does not use the eBPF datapath.
+func Update(netns, ifname, containerID string, ipv4 net.IP, prev, l []GWNets, opts Options) error {
+	pinPath := opts.BPFPinPath(containerID)
+	if pinPath == "" || ipv4 == nil || ipv4.To4() == nil {
+		return ErrNotPinned
+	}
+	if _, err := os.Stat(pinPath); errors.Is(err, os.ErrNotExist) {
+		return ErrNotPinned
+	}
+
+	return ns.WithNetNSPath(netns, func(_ ns.NetNS) error {
+		bc := founat.NewBPFClient(ifname, opts.EgressPort, ipv4, nil, pinPath)
+		if err := bc.Open(); err != nil {
+			return err
+		}
+		defer bc.Close()
+
+		current := make(map[string]bool)
+		for _, gwn := range l {
+			if gwn.Gateway.To4() == nil {
+				continue
+			}
+			if err := bc.AddEgress(gwn.Gateway, gwn.Networks); err != nil {
+				return err
+			}
+			for _, n := range gwn.Networks {
+				current[n.String()] = true
+			}
+		}
+
+		var stale []*net.IPNet
+		for _, gwn := range prev {
+			if gwn.Gateway.To4() == nil {
+				continue
+			}
+			for _, n := range gwn.Networks {
+				if !current[n.String()] {
+					stale = append(stale, n)
+				}
+			}
+		}
+		return bc.DelEgress(stale)
+	})
+}
+
+// EgressKeys returns the keys of Egresses and ClusterEgresses referenced
+// by the annotations of the pod.  Keys without namespace are for ClusterEgress.
+func EgressKeys(pod *corev1.Pod) []client.ObjectKey {
+	var keys []client.ObjectKey
+
+	for k, v := range pod.Annotations {
+		if k == constants.AnnClusterEgress {
+			for _, name := range strings.Split(v, ",") {
+				keys = append(keys, client.ObjectKey{Name: name})
+			}
+			continue
+		}
+		if !strings.HasPrefix(k, constants.AnnEgressPrefix) {
+			continue
+		}
+
+		ns := k[len(constants.AnnEgressPrefix):]
+		for _, name := range strings.Split(v, ",") {
+			keys = append(keys, client.ObjectKey{Namespace: ns, Name: name})
+		}
+	}
+	return keys
+}
+
+// GetGWNets returns the gateways and destination networks for the pod.
+func GetGWNets(ctx context.Context, r client.Reader, pod *corev1.Pod) ([]GWNets, error) {
+	if pod.Spec.HostNetwork {
+		// pods running in the host network cannot use egress NAT.
+		// In fact, such a pod won't call CNI, so this is just a safeguard.
+		return nil, nil
+	}
+
+	egNames := EgressKeys(pod)
+	if len(egNames) == 0 {
+		return nil, nil
+	}
+
+	podNS := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: pod.Namespace}, podNS); err != nil {
+		return nil, NewError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
+			"failed to get Namespace "+pod.Namespace, err.Error())
+	}
+
+	var gwlist []GWNets
+	for _, n := range egNames {
+		var spec *egressv1.EgressSpec
+		var allowed bool
+		var err error
+		kind := "Egress " + n.String()
+		svcKey := n
+		if n.Namespace == "" {
+			kind = "ClusterEgress " + n.Name
+			ce := &egressv1.ClusterEgress{}
+			if err := r.Get(ctx, n, ce); err != nil {
+				return nil, NewError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
+					"failed to get "+kind, err.Error())
+			}
+			spec = &ce.Spec.EgressSpec
+			svcKey = client.ObjectKey{Namespace: ce.Spec.Namespace, Name: ce.ResourceName()}
+			allowed, err = ce.IsNamespaceAllowed(podNS)
+		} else {
+			eg := &egressv1.Egress{}
+			if err := r.Get(ctx, n, eg); err != nil {
+				return nil, NewError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
+					"failed to get "+kind, err.Error())
+			}
+			spec = &eg.Spec
+			allowed, err = eg.IsNamespaceAllowed(podNS)
+		}
+		if err != nil {
+			return nil, NewInternalError(err, "invalid allowedNamespaces in "+kind)
+		}
+		if !allowed {
+			return nil, NewError(codes.PermissionDenied, cnirpc.ErrorCode_INTERNAL,
+				"namespace "+pod.Namespace+" is not allowed to use "+kind, "")
+		}
+
+		svc := &corev1.Service{}
+		if err := r.Get(ctx, svcKey, svc); err != nil {
+			return nil, NewError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
+				"failed to get Service "+svcKey.String(), err.Error())
+		}
+
+		// as of k8s 1.19, dual stack Service is alpha and will be re-written
+		// in 1.20.  So, we cannot use dual stack services.
+		svcIP := net.ParseIP(svc.Spec.ClusterIP)
+		if svcIP == nil {
+			return nil, NewError(codes.Internal, cnirpc.ErrorCode_INTERNAL,
+				"invalid ClusterIP in Service "+svcKey.String(), svc.Spec.ClusterIP)
+		}
+		var subnets []*net.IPNet
+		if ip4 := svcIP.To4(); ip4 != nil {
+			svcIP = ip4
+			for _, sn := range spec.ClientDestinations() {
+				_, subnet, err := net.ParseCIDR(sn)
+				if err != nil {
+					return nil, NewInternalError(err, "invalid network in "+kind)
+				}
+				if subnet.IP.To4() != nil {
+					subnets = append(subnets, subnet)
+				}
+			}
+		} else {
+			for _, sn := range spec.ClientDestinations() {
+				_, subnet, err := net.ParseCIDR(sn)
+				if err != nil {
+					return nil, NewInternalError(err, "invalid network in "+kind)
+				}
+				if subnet.IP.To4() == nil {
+					subnets = append(subnets, subnet)
+				}
+			}
+		}
+
+		if len(subnets) > 0 {
+			gwlist = append(gwlist, GWNets{Gateway: svcIP, Networks: subnets})
+		}
+	}
+
+	return gwlist, nil
+}
@@ -0,0 +1,234 @@
+package podnat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := egressv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cniVersion string
+		prevResult string
+		ipv4       string
+		ipv6       string
+	}{
+		{
+			name:       "cilium",
+			cniVersion: "1.0.0",
+			prevResult: `{
+				"cniVersion": "1.0.0",
+				"interfaces": [
+					{"name": "lxc1234", "mac": "00:11:22:33:44:55"},
+					{"name": "eth0", "mac": "99:88:77:66:55:44", "sandbox": "/var/run/netns/cni-1"}
+				],
+				"ips": [
+					{"address": "10.10.0.12/32", "interface": 1},
+					{"address": "fd00:10::12/128", "interface": 1}
+				]
+			}`,
+			ipv4: "10.10.0.12/32",
+			ipv6: "fd00:10::12/128",
+		},
+		{
+			name:       "bridge",
+			cniVersion: "0.3.1",
+			prevResult: `{
+				"cniVersion": "0.3.1",
+				"interfaces": [
+					{"name": "cni0", "mac": "00:11:22:33:44:55"},
+					{"name": "veth1234", "mac": "55:44:33:22:11:11"},
+					{"name": "eth0", "mac": "99:88:77:66:55:44", "sandbox": "/var/run/netns/cni-1"}
+				],
+				"ips": [
+					{"version": "4", "address": "10.244.1.5/24", "gateway": "10.244.1.1", "interface": 2}
+				]
+			}`,
+			ipv4: "10.244.1.5/24",
+		},
+		{
+			name:       "calico",
+			cniVersion: "0.3.1",
+			prevResult: `{
+				"cniVersion": "0.3.1",
+				"interfaces": [
+					{"name": "cali1234", "mac": "ee:ee:ee:ee:ee:ee"}
+				],
+				"ips": [
+					{"version": "4", "address": "192.168.1.5/32", "interface": 0},
+					{"version": "6", "address": "fd00:192::5/128", "interface": 0}
+				]
+			}`,
+			ipv4: "192.168.1.5/32",
+			ipv6: "fd00:192::5/128",
+		},
+		{
+			name:       "no interface",
+			cniVersion: "0.4.0",
+			prevResult: `{
+				"cniVersion": "0.4.0",
+				"ips": [
+					{"version": "4", "address": "192.168.1.5/32"}
+				]
+			}`,
+			ipv4: "192.168.1.5/32",
+		},
+		{
+			name:       "sandbox interface preferred",
+			cniVersion: "1.0.0",
+			prevResult: `{
+				"cniVersion": "1.0.0",
+				"interfaces": [
+					{"name": "veth1234"},
+					{"name": "net1", "sandbox": "/var/run/netns/cni-1"},
+					{"name": "eth0", "sandbox": "/var/run/netns/cni-1"}
+				],
+				"ips": [
+					{"address": "10.0.0.1/24", "interface": 0},
+					{"address": "10.0.1.5/24", "interface": 1},
+					{"address": "10.0.2.5/24", "interface": 2}
+				]
+			}`,
+			ipv4: "10.0.2.5/24",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdin := []byte(`{"cniVersion": "` + tc.cniVersion + `", "name": "k8s", "type": "egress-gw-cni", "prevResult": ` + tc.prevResult + `}`)
+			conf, result, err := ParseConfig(stdin, "eth0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result == nil {
+				t.Fatal("result should not be nil")
+			}
+
+			var ipv4, ipv6 string
+			if conf.ContIPv4.IP != nil {
+				ipv4 = conf.ContIPv4.String()
+			}
+			if conf.ContIPv6.IP != nil {
+				ipv6 = conf.ContIPv6.String()
+			}
+			if ipv4 != tc.ipv4 {
+				t.Errorf("unexpected IPv4 address: %q", ipv4)
+			}
+			if ipv6 != tc.ipv6 {
+				t.Errorf("unexpected IPv6 address: %q", ipv6)
+			}
+		})
+	}
+}
+
+func TestGetGWNets(t *testing.T) {
+	objs := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "internet"}},
+		&egressv1.Egress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "internet", Name: "egress"},
+			Spec: egressv1.EgressSpec{
+				Destinations: []egressv1.EgressDestination{{CIDR: "0.0.0.0/0"}, {CIDR: "::/0"}},
+			},
+		},
+		&egressv1.Egress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "internet", Name: "restricted"},
+			Spec: egressv1.EgressSpec{
+				Destinations: []egressv1.EgressDestination{{CIDR: "10.0.0.0/8"}},
+				AllowedNamespaces: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "internet", Name: "egress"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.10"},
+		},
+	}
+	c := newFakeClient(t, objs...)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "client",
+			Annotations: map[string]string{"egress.ysksuzuki.com/internet": "egress"},
+		},
+	}
+	gwnets, err := GetGWNets(context.Background(), c, pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gwnets) != 1 {
+		t.Fatalf("unexpected gateways: %v", gwnets)
+	}
+	if gwnets[0].Gateway.String() != "10.96.0.10" {
+		t.Error("unexpected gateway", gwnets[0].Gateway)
+	}
+	if len(gwnets[0].Networks) != 1 || gwnets[0].Networks[0].String() != "0.0.0.0/0" {
+		t.Error("only IPv4 networks should be routed to an IPv4 gateway", gwnets[0].Networks)
+	}
+
+	pod.Annotations = nil
+	gwnets, err = GetGWNets(context.Background(), c, pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gwnets != nil {
+		t.Error("pods without annotations should use no egress", gwnets)
+	}
+
+	pod.Annotations = map[string]string{"egress.ysksuzuki.com/internet": "restricted"}
+	_, err = GetGWNets(context.Background(), c, pod)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Error("namespace not allowed should be denied", err)
+	}
+
+	pod.Annotations = map[string]string{"egress.ysksuzuki.com/internet": "missing"}
+	_, err = GetGWNets(context.Background(), c, pod)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Error("missing Egress should be an error", err)
+	}
+}
+
+func TestUpdateNotPinned(t *testing.T) {
+	ipv4 := net.ParseIP("10.1.1.1")
+
+	err := Update("/run/netns/none", "eth0", "c1", ipv4, nil, nil, Options{BPFDatapath: true})
+	if !errors.Is(err, ErrNotPinned) {
+		t.Errorf("expected ErrNotPinned without BPFPinDir, got %v", err)
+	}
+
+	opts := Options{BPFDatapath: true, BPFPinDir: t.TempDir()}
+	if p := opts.BPFPinPath("c1"); p != filepath.Join(opts.BPFPinDir, "c1") {
+		t.Errorf("unexpected pin path: %s", p)
+	}
+	err = Update("/run/netns/none", "eth0", "c1", ipv4, nil, nil, opts)
+	if !errors.Is(err, ErrNotPinned) {
+		t.Errorf("expected ErrNotPinned without a pinned map, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package podstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned when no state is recorded for the container.
+var ErrNotFound = errors.New("pod state not found")
+
+const fileSuffix = ".json"
+
+// Gateway is a gateway and the destination networks routed to it.
+type Gateway struct {
+	Gateway  string   `json:"gateway"`
+	Networks []string `json:"networks"`
+}
+
+// Pod is the state of a pod configured by egress-gw-agent.
+type Pod struct {
+	ContainerID string    `json:"containerID"`
+	Netns       string    `json:"netns"`
+	Ifname      string    `json:"ifname"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Gateways    []Gateway `json:"gateways"`
+}
+
+// Store records Pod state in a directory, one file per container.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store that keeps files in `dir`.
+// `dir` is created if it does not exist.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(containerID string) (string, error) {
+	if containerID == "" || strings.ContainsAny(containerID, `/\`) || strings.HasPrefix(containerID, ".") {
+		return "", fmt.Errorf("invalid container ID: %q", containerID)
+	}
+	return filepath.Join(s.dir, containerID+fileSuffix), nil
+}
+
+// Save records `p` atomically.  An existing state for the same
+// container is replaced.
+func (s *Store) Save(p *Pod) error {
+	path, err := s.path(p.ContainerID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load returns the state of the container.
+// If no state is recorded, this returns ErrNotFound.
+func (s *Store) Load(containerID string) (*Pod, error) {
+	path, err := s.path(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pod{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Delete removes the state of the container.
+// It is not an error if no state is recorded.
+func (s *Store) Delete(containerID string) error {
+	path, err := s.path(containerID)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List returns all recorded states sorted by namespace, name, and container ID.
+func (s *Store) List() ([]*Pod, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []*Pod
+	for _, ent := range entries {
+		name := ent.Name()
+		if ent.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		p, err := s.Load(strings.TrimSuffix(name, fileSuffix))
+		if errors.Is(err, ErrNotFound) {
+			// removed after ReadDir
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, p)
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		if pods[i].Name != pods[j].Name {
+			return pods[i].Name < pods[j].Name
+		}
+		return pods[i].ContainerID < pods[j].ContainerID
+	})
+	return pods, nil
+}
@@ -0,0 +1,100 @@
+package podstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "egress-gw")
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 0 {
+		t.Errorf("unexpected pods: %v", pods)
+	}
+
+	p1 := &Pod{
+		ContainerID: "c1",
+		Netns:       "/run/netns/cni-1",
+		Ifname:      "eth0",
+		Namespace:   "default",
+		Name:        "pod1",
+		Gateways: []Gateway{
+			{Gateway: "10.96.0.10", Networks: []string{"0.0.0.0/0"}},
+		},
+	}
+	p2 := &Pod{
+		ContainerID: "c2",
+		Netns:       "/run/netns/cni-2",
+		Ifname:      "eth0",
+		Namespace:   "default",
+		Name:        "pod0",
+	}
+	for _, p := range []*Pod{p1, p2} {
+		if err := s.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := s.Load("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, p1) {
+		t.Errorf("unexpected state: %+v", loaded)
+	}
+
+	pods, err = s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pods, []*Pod{p2, p1}) {
+		t.Errorf("unexpected pods: %+v", pods)
+	}
+
+	p1.Gateways = append(p1.Gateways, Gateway{Gateway: "fd00::10", Networks: []string{"::/0"}})
+	if err := s.Save(p1); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = s.Load("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, p1) {
+		t.Errorf("state was not replaced: %+v", loaded)
+	}
+
+	if err := s.Delete("c1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("c1"); err != nil {
+		t.Error("deleting a missing state should succeed", err)
+	}
+	if _, err := s.Load("c1"); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound, got", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "c2.json" {
+		t.Errorf("unexpected files in the directory: %v", entries)
+	}
+
+	for _, id := range []string{"", "../c1", ".tmp-1"} {
+		if err := s.Save(&Pod{ContainerID: id}); err == nil {
+			t.Errorf("invalid container ID %q should be rejected", id)
+		}
+	}
+}
@@ -2,67 +2,97 @@ package runners
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
-	"strings"
+	"time"
 
-	"github.com/containernetworking/cni/pkg/types"
-	current "github.com/containernetworking/cni/pkg/types/100"
-	"github.com/containernetworking/cni/pkg/version"
-	"github.com/containernetworking/plugins/pkg/ns"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
-	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
 	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/founat"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podnat"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podstate"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 // NewEgressGwAgent returns an implementation of cnirpc.CNIServer for egress-gw.
-func NewEgressGwAgent(l net.Listener, mgr manager.Manager, egressPort int, logger *zap.Logger) manager.Runnable {
+//
+// If `opts.BPFDatapath` is true, IPv4 egress traffic of client pods is
+// steered by an eBPF program instead of policy routing.  If the maps of
+// the program are pinned, the agent updates their destinations when
+// Egresses are changed.
+//
+// The agent records pods it has configured in `store` so that the state
+// survives restarts of the agent.
+//
+// The introspection service is served on `l` along with the CNI service.
+// If `il` is not nil, the introspection service is also served on it.
+func NewEgressGwAgent(l, il net.Listener, mgr manager.Manager, store *podstate.Store, opts podnat.Options, logger *zap.Logger) manager.Runnable {
 	return &egressGwAgent{
-		listener:   l,
-		apiReader:  mgr.GetAPIReader(),
-		client:     mgr.GetClient(),
-		egressPort: egressPort,
-		logger:     logger,
+		listener:              l,
+		introspectionListener: il,
+		apiReader:             mgr.GetAPIReader(),
+		client:                mgr.GetClient(),
+		cache:                 mgr.GetCache(),
+		natOptions:            opts,
+		logger:                logger,
+		store:                 store,
 	}
 }
 
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get
-// +kubebuilder:rbac:groups="",resources=namespaces;services,verbs=get;list;watch
-// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods;namespaces;services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses;clusteregresses,verbs=get;list;watch
 
-var grpcMetrics = grpc_prometheus.NewServerMetrics()
+// cacheSyncTimeout is the time to wait for the cache to be synced in STATUS.
+const cacheSyncTimeout = 5 * time.Second
+
+var (
+	grpcMetrics = grpc_prometheus.NewServerMetrics()
+
+	cacheReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: constants.MetricsNS,
+			Subsystem: "agent",
+			Name:      "cache_reads_total",
+			Help:      "the number of reads from the informer cache by the result",
+		},
+		[]string{"resource", "result"},
+	)
+)
 
 func init() {
 	// register grpc_prometheus with controller-runtime's Registry
 	metrics.Registry.MustRegister(grpcMetrics)
+	metrics.Registry.MustRegister(cacheReads)
 }
 
 type egressGwAgent struct {
 	cnirpc.UnimplementedCNIServer
-	listener   net.Listener
-	apiReader  client.Reader
-	client     client.Client
-	egressPort int
-	logger     *zap.Logger
+	listener              net.Listener
+	introspectionListener net.Listener
+	apiReader             client.Reader
+	client                client.Client
+	cache                 cache.Cache
+	natOptions            podnat.Options
+	logger                *zap.Logger
+	store                 *podstate.Store
 }
 
 func (e *egressGwAgent) Start(ctx context.Context) error {
@@ -74,6 +104,7 @@ func (e *egressGwAgent) Start(ctx context.Context) error {
 		),
 	))
 	cnirpc.RegisterCNIServer(grpcServer, e)
+	cnirpc.RegisterIntrospectionServer(grpcServer, &introspectionServer{store: e.store})
 
 	// after all services are registered, initialize metrics.
 	grpcMetrics.InitializeMetrics(grpcServer)
@@ -87,7 +118,34 @@ func (e *egressGwAgent) Start(ctx context.Context) error {
 		grpcServer.GracefulStop()
 	}()
 
-	return grpcServer.Serve(e.listener)
+	if e.natOptions.BPFDatapath && e.natOptions.BPFPinDir != "" {
+		go e.syncBPF(ctx)
+	}
+
+	if e.introspectionListener == nil {
+		return grpcServer.Serve(e.listener)
+	}
+
+	// the TCP listener serves only the introspection service.
+	introspection := grpc.NewServer(grpc.UnaryInterceptor(grpc_zap.UnaryServerInterceptor(e.logger)))
+	cnirpc.RegisterIntrospectionServer(introspection, &introspectionServer{store: e.store})
+	reflection.Register(introspection)
+	go func() {
+		<-ctx.Done()
+		introspection.GracefulStop()
+	}()
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- grpcServer.Serve(e.listener)
+	}()
+	go func() {
+		errCh <- introspection.Serve(e.introspectionListener)
+	}()
+	err := <-errCh
+	grpcServer.Stop()
+	introspection.Stop()
+	return err
 }
 
 func fieldExtractor(fullMethod string, req interface{}) map[string]interface{} {
@@ -109,262 +167,212 @@ func fieldExtractor(fullMethod string, req interface{}) map[string]interface{} {
 	return ret
 }
 
-func newError(c codes.Code, cniCode cnirpc.ErrorCode, msg, details string) error {
-	st := status.New(c, msg)
-	st, err := st.WithDetails(&cnirpc.CNIError{Code: cniCode, Msg: msg, Details: details})
+func (e *egressGwAgent) Add(ctx context.Context, args *cnirpc.CNIArgs) (*cnirpc.AddResponse, error) {
+	logger := ctxzap.Extract(ctx)
+
+	reader := &fallbackReader{cache: e.client, apiReader: e.apiReader}
+	result, g, err := podnat.Add(ctx, reader, args, e.natOptions, logger)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return st.Err()
+	if g != nil {
+		podName := args.Args[constants.PodNameKey]
+		podNS := args.Args[constants.PodNamespaceKey]
+		if err := e.store.Save(newPodState(podNS, podName, args, g)); err != nil {
+			logger.Sugar().Errorw("failed to save pod state", "error", err)
+			return nil, podnat.NewInternalError(err, "failed to save pod state")
+		}
+	}
+
+	return &cnirpc.AddResponse{Result: result}, nil
 }
 
-func newInternalError(err error, msg string) error {
-	return newError(codes.Internal, cnirpc.ErrorCode_INTERNAL, msg, err.Error())
+// fallbackReader reads objects from the informer cache.  If an object is
+// not in the cache, it reads the object from the API server because the
+// cache may not have caught up with objects created just now.
+type fallbackReader struct {
+	cache     client.Reader
+	apiReader client.Reader
 }
 
-type PluginConf struct {
-	types.NetConf
+var _ client.Reader = &fallbackReader{}
+
+func (r *fallbackReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	resource := resourceName(obj)
+	err := r.cache.Get(ctx, key, obj, opts...)
+	if err == nil {
+		cacheReads.WithLabelValues(resource, "hit").Inc()
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
 
-	// These are fields parsed out of the config or the environment;
-	// included here for convenience
-	ContainerID string    `json:"-"`
-	ContIPv4    net.IPNet `json:"-"`
-	ContIPv6    net.IPNet `json:"-"`
+	cacheReads.WithLabelValues(resource, "miss").Inc()
+	return r.apiReader.Get(ctx, key, obj, opts...)
 }
 
-// parseConfig parses the supplied configuration (and prevResult) from stdin.
-func parseConfig(stdin []byte, ifName string) (*PluginConf, *current.Result, error) {
-	conf := PluginConf{}
+func (r *fallbackReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return r.cache.List(ctx, list, opts...)
+}
 
-	if err := json.Unmarshal(stdin, &conf); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse network configuration: %v", err)
+func resourceName(obj client.Object) string {
+	switch obj.(type) {
+	case *corev1.Pod:
+		return "pods"
+	case *corev1.Namespace:
+		return "namespaces"
+	case *corev1.Service:
+		return "services"
+	case *egressv1.Egress:
+		return "egresses"
+	case *egressv1.ClusterEgress:
+		return "clusteregresses"
 	}
+	return "unknown"
+}
 
-	// Parse previous result.
-	var result *current.Result
-	if conf.RawPrevResult != nil {
-		var err error
-		if err = version.ParsePrevResult(&conf.NetConf); err != nil {
-			return nil, nil, fmt.Errorf("could not parse prevResult: %v", err)
-		}
+func newPodState(namespace, name string, args *cnirpc.CNIArgs, l []podnat.GWNets) *podstate.Pod {
+	return &podstate.Pod{
+		ContainerID: args.ContainerId,
+		Netns:       args.Netns,
+		Ifname:      args.Ifname,
+		Namespace:   namespace,
+		Name:        name,
+		Gateways:    newGateways(l),
+	}
+}
 
-		result, err = current.NewResultFromResult(conf.PrevResult)
-		if err != nil {
-			return nil, nil, fmt.Errorf("could not convert result to current version: %v", err)
+func newGateways(l []podnat.GWNets) []podstate.Gateway {
+	var gws []podstate.Gateway
+	for _, gwn := range l {
+		g := podstate.Gateway{Gateway: gwn.Gateway.String()}
+		for _, n := range gwn.Networks {
+			g.Networks = append(g.Networks, n.String())
 		}
+		gws = append(gws, g)
 	}
+	return gws
+}
 
-	if conf.PrevResult != nil {
-		for _, ip := range result.IPs {
-			isIPv4 := ip.Address.IP.To4() != nil
-			if !isIPv4 && conf.ContIPv6.IP != nil {
-				continue
-			} else if isIPv4 && conf.ContIPv4.IP != nil {
-				continue
-			}
-
-			// Skip known non-sandbox interfaces
-			if ip.Interface != nil {
-				intIdx := *ip.Interface
-				if intIdx >= 0 &&
-					intIdx < len(result.Interfaces) &&
-					(result.Interfaces[intIdx].Name != ifName ||
-						result.Interfaces[intIdx].Sandbox == "") {
-					continue
-				}
-			}
-			if ip.Address.IP.To4() != nil {
-				conf.ContIPv4 = ip.Address
-			} else {
-				conf.ContIPv6 = ip.Address
-			}
-		}
+func (e *egressGwAgent) Del(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
+	logger := ctxzap.Extract(ctx)
+
+	// TODO
+	logger.Sugar().Info("perform DEL")
+	if err := founat.UnpinBPF(e.natOptions.BPFPinPath(args.ContainerId)); err != nil {
+		logger.Sugar().Errorw("failed to unpin eBPF map", "error", err)
+		return nil, podnat.NewInternalError(err, "failed to unpin eBPF map")
+	}
+	if err := e.store.Delete(args.ContainerId); err != nil {
+		logger.Sugar().Errorw("failed to delete pod state", "error", err)
+		return nil, podnat.NewInternalError(err, "failed to delete pod state")
 	}
 
-	return &conf, result, nil
+	return &emptypb.Empty{}, nil
 }
 
-func (e *egressGwAgent) Add(ctx context.Context, args *cnirpc.CNIArgs) (*cnirpc.AddResponse, error) {
+// Check verifies that the container is configured as recorded by ADD.
+// Containers of pods that do not use egress have no state and always pass.
+func (e *egressGwAgent) Check(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
 	logger := ctxzap.Extract(ctx)
 
+	p, err := e.store.Load(args.ContainerId)
+	if errors.Is(err, podstate.ErrNotFound) {
+		return e.checkNoState(ctx, args)
+	}
+	if err != nil {
+		logger.Sugar().Errorw("failed to load pod state", "error", err)
+		return nil, podnat.NewInternalError(err, "failed to load pod state")
+	}
+
+	if p.Netns != args.Netns || p.Ifname != args.Ifname {
+		return nil, podnat.NewError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
+			"container is configured for another attachment",
+			fmt.Sprintf("netns=%s ifname=%s", p.Netns, p.Ifname))
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// checkNoState returns an error if the pod of the container uses egress
+// because ADD should have recorded its state.
+func (e *egressGwAgent) checkNoState(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
 	podName := args.Args[constants.PodNameKey]
 	podNS := args.Args[constants.PodNamespaceKey]
 	if podName == "" || podNS == "" {
-		logger.Sugar().Errorw("missing pod name/namespace", "args", args.Args)
-		return nil, newError(codes.InvalidArgument, cnirpc.ErrorCode_INVALID_ENVIRONMENT_VARIABLES,
+		return nil, podnat.NewError(codes.InvalidArgument, cnirpc.ErrorCode_INVALID_ENVIRONMENT_VARIABLES,
 			"missing pod name/namespace", fmt.Sprintf("%+v", args.Args))
 	}
 
+	reader := &fallbackReader{cache: e.client, apiReader: e.apiReader}
 	pod := &corev1.Pod{}
-	if err := e.apiReader.Get(ctx, client.ObjectKey{Namespace: podNS, Name: podName}, pod); err != nil {
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: podNS, Name: podName}, pod); err != nil {
 		if apierrors.IsNotFound(err) {
-			logger.Sugar().Errorw("pod not found", "name", podName, "namespace", podNS)
-			return nil, newError(codes.NotFound, cnirpc.ErrorCode_UNKNOWN_CONTAINER, "pod not found", err.Error())
+			return nil, podnat.NewError(codes.NotFound, cnirpc.ErrorCode_UNKNOWN_CONTAINER, "pod not found", err.Error())
 		}
-		logger.Sugar().Errorw("failed to get pod", "name", podName, "namespace", podNS, "error", err)
-		return nil, newInternalError(err, "failed to get pod")
+		return nil, podnat.NewInternalError(err, "failed to get pod")
 	}
 
-	g, err := e.getGWNets(ctx, pod)
-	if err != nil {
-		logger.Sugar().Errorw("failed to get egress GW", "error", err)
-		return nil, newInternalError(err, "failed to get egress GW")
+	if len(podnat.EgressKeys(pod)) > 0 {
+		return nil, podnat.NewError(codes.NotFound, cnirpc.ErrorCode_UNKNOWN_CONTAINER,
+			"no state is recorded for the container", args.ContainerId)
 	}
+	return &emptypb.Empty{}, nil
+}
 
-	n, prevRes, err := parseConfig(args.StdinData, args.Ifname)
-	if err != nil {
-		return nil, newError(codes.InvalidArgument, cnirpc.ErrorCode_DECODING_FAILURE,
-			"unable to parse CNI configuration", fmt.Sprintf("%+v", args.Args))
+// Status reports whether the agent is ready to handle ADD.
+func (e *egressGwAgent) Status(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
+	syncCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+	if !e.cache.WaitForCacheSync(syncCtx) {
+		return nil, podnat.NewError(codes.Unavailable, cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE, "cache is not synced", "")
 	}
 
-	if g != nil {
-		logger.Sugar().Info("enabling egress GW")
-		err = ns.WithNetNSPath(args.Netns, func(_ ns.NetNS) error {
-			if err := e.setupEgressGW(n.ContIPv4.IP, n.ContIPv6.IP, g, logger); err != nil {
-				return err
-			}
-			return nil
-		})
-
-		if err != nil {
-			logger.Sugar().Errorw("failed to setup egress GW", "error", err)
-			return nil, newInternalError(err, "failed to setup egress GW")
-		}
+	if _, err := e.store.List(); err != nil {
+		return nil, podnat.NewError(codes.Unavailable, cnirpc.ErrorCode_PLUGIN_NOT_AVAILABLE, "failed to read pod state", err.Error())
 	}
 
-	data, err := json.Marshal(prevRes)
-	if err != nil {
-		logger.Sugar().Errorw("failed to marshal the result", "error", err)
-		return nil, newInternalError(err, "failed to marshal the result")
-	}
-	return &cnirpc.AddResponse{Result: data}, nil
-}
-
-type GWNets struct {
-	Gateway  net.IP
-	Networks []*net.IPNet
+	return &emptypb.Empty{}, nil
 }
 
-func (e *egressGwAgent) setupEgressGW(ipv4, ipv6 net.IP, l []GWNets, log *zap.Logger) error {
-	ft := founat.NewFoUTunnel(0, e.egressPort, ipv4, ipv6)
-	if err := ft.Init(); err != nil {
-		return err
-	}
+// GC removes states and pinned eBPF maps of pods whose attachments are no
+// longer valid.  Other objects such as links, routes, and eBPF programs are
+// in the network namespaces of pods and are removed along with them.
+func (e *egressGwAgent) GC(ctx context.Context, args *cnirpc.GCArgs) (*emptypb.Empty, error) {
+	logger := ctxzap.Extract(ctx)
 
-	cl := founat.NewNatClient(ipv4, ipv6, nil)
-	if err := cl.Init(); err != nil {
-		return err
+	type attachment struct {
+		containerID string
+		ifname      string
 	}
-
-	for _, gwn := range l {
-		link, err := ft.AddPeer(gwn.Gateway)
-		if errors.Is(err, founat.ErrIPFamilyMismatch) {
-			// ignore unsupported IP family link
-			log.Sugar().Infow("ignored unsupported gateway", "gw", gwn.Gateway)
-			continue
-		}
-		if err != nil {
-			return err
-		}
-		if err := cl.AddEgress(link, gwn.Networks); err != nil {
-			return err
-		}
+	valid := make(map[attachment]bool)
+	for _, a := range args.ValidAttachments {
+		valid[attachment{a.ContainerId, a.Ifname}] = true
 	}
 
-	return nil
-}
-
-func (e *egressGwAgent) getGWNets(ctx context.Context, pod *corev1.Pod) ([]GWNets, error) {
-	if pod.Spec.HostNetwork {
-		// pods running in the host network cannot use egress NAT.
-		// In fact, such a pod won't call CNI, so this is just a safeguard.
-		return nil, nil
+	pods, err := e.store.List()
+	if err != nil {
+		logger.Sugar().Errorw("failed to list pod states", "error", err)
+		return nil, podnat.NewInternalError(err, "failed to list pod states")
 	}
-
-	var egNames []client.ObjectKey
-
-	for k, v := range pod.Annotations {
-		if !strings.HasPrefix(k, constants.AnnEgressPrefix) {
+	for _, p := range pods {
+		if valid[attachment{p.ContainerID, p.Ifname}] {
 			continue
 		}
 
-		ns := k[len(constants.AnnEgressPrefix):]
-		for _, name := range strings.Split(v, ",") {
-			egNames = append(egNames, client.ObjectKey{Namespace: ns, Name: name})
+		logger.Sugar().Infow("removing stale pod state", "container_id", p.ContainerID, "ifname", p.Ifname,
+			"pod.namespace", p.Namespace, "pod.name", p.Name)
+		if err := founat.UnpinBPF(e.natOptions.BPFPinPath(p.ContainerID)); err != nil {
+			logger.Sugar().Errorw("failed to unpin eBPF map", "error", err)
+			return nil, podnat.NewInternalError(err, "failed to unpin eBPF map")
 		}
-	}
-	if len(egNames) == 0 {
-		return nil, nil
-	}
-
-	var gwlist []GWNets
-	for _, n := range egNames {
-		eg := &egressv1beta1.Egress{}
-		svc := &corev1.Service{}
-
-		if err := e.client.Get(ctx, n, eg); err != nil {
-			return nil, newError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
-				"failed to get Egress "+n.String(), err.Error())
-		}
-		if err := e.client.Get(ctx, n, svc); err != nil {
-			return nil, newError(codes.FailedPrecondition, cnirpc.ErrorCode_INTERNAL,
-				"failed to get Service "+n.String(), err.Error())
-		}
-
-		// as of k8s 1.19, dual stack Service is alpha and will be re-written
-		// in 1.20.  So, we cannot use dual stack services.
-		svcIP := net.ParseIP(svc.Spec.ClusterIP)
-		if svcIP == nil {
-			return nil, newError(codes.Internal, cnirpc.ErrorCode_INTERNAL,
-				"invalid ClusterIP in Service "+n.String(), svc.Spec.ClusterIP)
-		}
-		var subnets []*net.IPNet
-		if ip4 := svcIP.To4(); ip4 != nil {
-			svcIP = ip4
-			for _, sn := range eg.Spec.Destinations {
-				_, subnet, err := net.ParseCIDR(sn)
-				if err != nil {
-					return nil, newInternalError(err, "invalid network in Egress "+n.String())
-				}
-				if subnet.IP.To4() != nil {
-					subnets = append(subnets, subnet)
-				}
-			}
-		} else {
-			for _, sn := range eg.Spec.Destinations {
-				_, subnet, err := net.ParseCIDR(sn)
-				if err != nil {
-					return nil, newInternalError(err, "invalid network in Egress "+n.String())
-				}
-				if subnet.IP.To4() == nil {
-					subnets = append(subnets, subnet)
-				}
-			}
-		}
-
-		if len(subnets) > 0 {
-			gwlist = append(gwlist, GWNets{Gateway: svcIP, Networks: subnets})
+		if err := e.store.Delete(p.ContainerID); err != nil {
+			logger.Sugar().Errorw("failed to delete pod state", "error", err)
+			return nil, podnat.NewInternalError(err, "failed to delete pod state")
 		}
 	}
 
-	return gwlist, nil
-}
-
-func (e *egressGwAgent) Del(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
-	logger := ctxzap.Extract(ctx)
-
-	// TODO
-	logger.Sugar().Info("perform DEL")
-
-	return &emptypb.Empty{}, nil
-}
-
-func (e *egressGwAgent) Check(ctx context.Context, args *cnirpc.CNIArgs) (*emptypb.Empty, error) {
-	logger := ctxzap.Extract(ctx)
-
-	// TODO
-	logger.Sugar().Info("perform CHECK")
-
 	return &emptypb.Empty{}, nil
 }
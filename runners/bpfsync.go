@@ -0,0 +1,157 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podnat"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podstate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bpfSyncInterval is the interval to update destinations of the eBPF
+// datapath of configured pods.
+const bpfSyncInterval = 30 * time.Second
+
+// syncBPF updates the eBPF datapath of pods recorded in the store until
+// `ctx` is done, so that changes of Egresses and their Services are
+// applied to pods configured before the changes.
+func (e *egressGwAgent) syncBPF(ctx context.Context) {
+	ticker := time.NewTicker(bpfSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pods, err := e.store.List()
+		if err != nil {
+			e.logger.Sugar().Errorw("failed to list pod states", "error", err)
+			continue
+		}
+
+		reader := &fallbackReader{cache: e.client, apiReader: e.apiReader}
+		for _, p := range pods {
+			if err := e.syncBPFPod(ctx, reader, p); err != nil {
+				e.logger.Sugar().Errorw("failed to update eBPF datapath", "container_id", p.ContainerID,
+					"pod.namespace", p.Namespace, "pod.name", p.Name, "error", err)
+			}
+		}
+	}
+}
+
+// syncBPFPod updates the eBPF datapath of `p` if its gateways are changed.
+func (e *egressGwAgent) syncBPFPod(ctx context.Context, r client.Reader, p *podstate.Pod) error {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: p.Name}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			// DEL will remove the state
+			return nil
+		}
+		return err
+	}
+
+	l, err := podnat.GetGWNets(ctx, r, pod)
+	if err != nil {
+		return err
+	}
+	// IPv6 gateways are not steered by the eBPF datapath, so they are
+	// kept as they are.
+	gws := append(filterGateways(newGateways(l), true), filterGateways(p.Gateways, false)...)
+	if sameGateways(gws, p.Gateways) {
+		return nil
+	}
+
+	prev, err := parseGateways(p.Gateways)
+	if err != nil {
+		return err
+	}
+	var ipv4 net.IP
+	for _, ip := range pod.Status.PodIPs {
+		if ip4 := net.ParseIP(ip.IP).To4(); ip4 != nil {
+			ipv4 = ip4
+			break
+		}
+	}
+
+	err = podnat.Update(p.Netns, p.Ifname, p.ContainerID, ipv4, prev, l, e.natOptions)
+	if errors.Is(err, podnat.ErrNotPinned) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	e.logger.Sugar().Infow("updated eBPF datapath", "container_id", p.ContainerID,
+		"pod.namespace", p.Namespace, "pod.name", p.Name)
+	p.Gateways = gws
+	return e.store.Save(p)
+}
+
+// filterGateways returns IPv4 gateways in `gws` if `ipv4` is true, or
+// the others if false.
+func filterGateways(gws []podstate.Gateway, ipv4 bool) []podstate.Gateway {
+	var l []podstate.Gateway
+	for _, g := range gws {
+		ip := net.ParseIP(g.Gateway)
+		if (ip != nil && ip.To4() != nil) == ipv4 {
+			l = append(l, g)
+		}
+	}
+	return l
+}
+
+// sameGateways returns true if `a` and `b` have the same gateways and
+// networks regardless of the order.
+func sameGateways(a, b []podstate.Gateway) bool {
+	flatten := func(gws []podstate.Gateway) []string {
+		var l []string
+		for _, g := range gws {
+			for _, n := range g.Networks {
+				l = append(l, g.Gateway+" "+n)
+			}
+		}
+		sort.Strings(l)
+		return l
+	}
+
+	fa, fb := flatten(a), flatten(b)
+	if len(fa) != len(fb) {
+		return false
+	}
+	for i := range fa {
+		if fa[i] != fb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func parseGateways(gws []podstate.Gateway) ([]podnat.GWNets, error) {
+	var l []podnat.GWNets
+	for _, g := range gws {
+		gw := net.ParseIP(g.Gateway)
+		if gw == nil {
+			return nil, fmt.Errorf("invalid gateway %q", g.Gateway)
+		}
+		gwn := podnat.GWNets{Gateway: gw}
+		for _, sn := range g.Networks {
+			_, n, err := net.ParseCIDR(sn)
+			if err != nil {
+				return nil, err
+			}
+			gwn.Networks = append(gwn.Networks, n)
+		}
+		l = append(l, gwn)
+	}
+	return l, nil
+}
@@ -0,0 +1,111 @@
+package runners
+
+import (
+	"context"
+
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/cnirpc"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/netinspect"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/podstate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func podStateProto(p *podstate.Pod) *cnirpc.PodState {
+	st := &cnirpc.PodState{
+		Namespace:   p.Namespace,
+		Name:        p.Name,
+		ContainerId: p.ContainerID,
+		Netns:       p.Netns,
+		Ifname:      p.Ifname,
+	}
+	for _, g := range p.Gateways {
+		st.Gateways = append(st.Gateways, &cnirpc.GatewayNetworks{
+			Gateway:  g.Gateway,
+			Networks: g.Networks,
+		})
+	}
+	return st
+}
+
+// introspectionServer implements cnirpc.IntrospectionServer.
+type introspectionServer struct {
+	cnirpc.UnimplementedIntrospectionServer
+	store *podstate.Store
+}
+
+func (s *introspectionServer) ListPods(ctx context.Context, req *cnirpc.ListPodsRequest) (*cnirpc.ListPodsResponse, error) {
+	list, err := s.store.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list pods: %v", err)
+	}
+
+	pods := make([]*cnirpc.PodState, 0, len(list))
+	for _, p := range list {
+		st := podStateProto(p)
+		if req.Netlink {
+			fillNetlinkState(st)
+		}
+		pods = append(pods, st)
+	}
+	return &cnirpc.ListPodsResponse{Pods: pods}, nil
+}
+
+func (s *introspectionServer) GetPod(ctx context.Context, req *cnirpc.GetPodRequest) (*cnirpc.PodState, error) {
+	list, err := s.store.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list pods: %v", err)
+	}
+
+	// a pod may have an old sandbox being deleted; the last one wins.
+	var found *podstate.Pod
+	for _, p := range list {
+		if p.Namespace == req.Namespace && p.Name == req.Name {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, status.Errorf(codes.NotFound, "pod %s/%s is not configured by this agent", req.Namespace, req.Name)
+	}
+	st := podStateProto(found)
+	fillNetlinkState(st)
+	return st, nil
+}
+
+func fillNetlinkState(st *cnirpc.PodState) {
+	ns, err := netinspect.Dump(st.Netns)
+	if err != nil {
+		st.NetlinkError = err.Error()
+		return
+	}
+
+	nl := &cnirpc.NetlinkState{}
+	for _, l := range ns.Links {
+		nl.Links = append(nl.Links, &cnirpc.Link{
+			Name:       l.Name,
+			Index:      int32(l.Index),
+			Type:       l.Type,
+			Up:         l.Up,
+			Remote:     l.Remote,
+			EncapDport: uint32(l.EncapDport),
+		})
+	}
+	for _, r := range ns.Routes {
+		nl.Routes = append(nl.Routes, &cnirpc.Route{
+			Table:    int32(r.Table),
+			Dst:      r.Dst,
+			Gateway:  r.Gateway,
+			Dev:      r.Dev,
+			Protocol: int32(r.Protocol),
+		})
+	}
+	for _, r := range ns.Rules {
+		nl.Rules = append(nl.Rules, &cnirpc.Rule{
+			Priority: int32(r.Priority),
+			Table:    int32(r.Table),
+			Src:      r.Src,
+			Dst:      r.Dst,
+			Mark:     r.Mark,
+		})
+	}
+	st.Netlink = nl
+}
@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	egressv1beta1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Egress conversion", func() {
+	It("should serve v1beta1 Egresses", func() {
+		old := &egressv1beta1.Egress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "v1beta1",
+				Namespace: "default",
+			},
+			Spec: egressv1beta1.EgressSpec{
+				Destinations: []string{"10.4.0.0/16", "fd04::1/120"},
+				NextHops: []egressv1beta1.EgressNextHop{
+					{Destination: "10.4.0.0/16", NextHop: "192.0.2.1"},
+					{Destination: "10.4.1.0/24", NextHop: "192.0.2.2"},
+				},
+				Replicas: 1,
+			},
+		}
+		err := k8sClient.Create(ctx, old)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(old.Spec.Destinations).To(Equal([]string{"10.4.0.0/16", "fd04::/120"}))
+
+		eg := &egressv1.Egress{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "v1beta1"}, eg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eg.Spec.Destinations).To(Equal([]egressv1.EgressDestination{
+			{CIDR: "10.4.0.0/16", NextHop: "192.0.2.1"},
+			{CIDR: "fd04::/120"},
+			{CIDR: "10.4.1.0/24", NextHop: "192.0.2.2"},
+		}))
+
+		By("updating the v1beta1 Egress")
+		old.Spec.Replicas = 2
+		old.Spec.Destinations = append(old.Spec.Destinations, "10.5.0.0/16")
+		err = k8sClient.Update(ctx, old)
+		Expect(err).To(HaveOccurred())
+
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "v1beta1"}, old)
+		Expect(err).NotTo(HaveOccurred())
+		old.Spec.Replicas = 2
+		err = k8sClient.Update(ctx, old)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate v1beta1 Egresses", func() {
+		old := &egressv1beta1.Egress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "v1beta1-invalid",
+				Namespace: "default",
+			},
+			Spec: egressv1beta1.EgressSpec{
+				Destinations: []string{"127.0.0.0/8"},
+				Replicas:     1,
+			},
+		}
+		err := k8sClient.Create(ctx, old)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,234 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupPodWebhookWithManager setups the mutating and validating webhooks for client Pods.
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(&podDefaulter{}).
+		WithValidator(&podValidator{client: mgr.GetClient()}).
+		Complete()
+}
+
+// Pods are validated with failurePolicy=ignore so that an unavailable
+// controller does not block every pod creation in the cluster.
+// Pods in system namespaces are excluded by namespaceSelector set in
+// config/default/webhook_manifests_patch.yaml.tmpl, as the markers
+// cannot specify it.
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=egress.ysksuzuki.com,resources=egresses;clusteregresses,verbs=get;list;watch
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate--v1-pod,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=vpod.kb.io,admissionReviewVersions=v1
+
+type podDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &podDefaulter{}
+
+// Default implements webhook.CustomDefaulter.
+//
+// Pods having egress annotations are labeled with constants.LabelEgressClient
+// so that egress pods can watch only client pods.
+func (d *podDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	if !hasEgressAnnotations(pod) {
+		return nil
+	}
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string)
+	}
+	pod.Labels[constants.LabelEgressClient] = "true"
+	return nil
+}
+
+func hasEgressAnnotations(pod *corev1.Pod) bool {
+	for k := range pod.Annotations {
+		if k == constants.AnnClusterEgress || strings.HasPrefix(k, constants.AnnEgressPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type podValidator struct {
+	client client.Client
+}
+
+var _ webhook.CustomValidator = &podValidator{}
+
+// ValidateCreate implements webhook.CustomValidator.
+//
+// Malformed annotations, host network pods, and references to Egresses
+// or ClusterEgresses that do not allow the namespace of the pod are rejected.
+// Missing namespaces, Egresses, or ClusterEgresses only produce warnings
+// because they may be created after the pod.
+func (v *podValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	refs, errs := parseEgressAnnotations(pod)
+	if len(refs) > 0 && pod.Spec.HostNetwork {
+		p := field.NewPath("spec", "hostNetwork")
+		errs = append(errs, field.Forbidden(p, "pods running in the host network cannot use egress NAT"))
+	}
+	if len(errs) > 0 {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, podName(pod), errs)
+	}
+
+	podNS := pod.Namespace
+	if podNS == "" {
+		req, err := admission.RequestFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		podNS = req.Namespace
+	}
+	ns := &corev1.Namespace{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: podNS}, ns); err != nil {
+		return nil, err
+	}
+
+	var warnings admission.Warnings
+	checked := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.Namespace == "" {
+			ce := &egressv1.ClusterEgress{}
+			err := v.client.Get(ctx, ref, ce)
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			if err != nil {
+				warnings = append(warnings, "ClusterEgress "+ref.Name+" does not exist")
+				continue
+			}
+
+			allowed, err := ce.IsNamespaceAllowed(ns)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				p := field.NewPath("metadata", "annotations").Key(constants.AnnClusterEgress)
+				errs = append(errs, field.Forbidden(p, "namespace "+podNS+" is not allowed to use ClusterEgress "+ref.Name))
+			}
+			continue
+		}
+
+		if _, ok := checked[ref.Namespace]; !ok {
+			err := v.client.Get(ctx, client.ObjectKey{Name: ref.Namespace}, &corev1.Namespace{})
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			checked[ref.Namespace] = err == nil
+			if err != nil {
+				warnings = append(warnings, "namespace "+ref.Namespace+" does not exist")
+			}
+		}
+		if !checked[ref.Namespace] {
+			continue
+		}
+
+		eg := &egressv1.Egress{}
+		err := v.client.Get(ctx, ref, eg)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		if err != nil {
+			warnings = append(warnings, "Egress "+ref.String()+" does not exist")
+			continue
+		}
+
+		allowed, err := eg.IsNamespaceAllowed(ns)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			p := field.NewPath("metadata", "annotations").Key(constants.AnnEgressPrefix + ref.Namespace)
+			errs = append(errs, field.Forbidden(p, "namespace "+podNS+" is not allowed to use Egress "+ref.String()))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, podName(pod), errs)
+	}
+
+	return warnings, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+// Egress annotations are only read when the pod is created.
+func (v *podValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (v *podValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// parseEgressAnnotations returns the Egresses referenced by the pod annotations.
+// ClusterEgresses are returned as keys without namespace.
+func parseEgressAnnotations(pod *corev1.Pod) ([]client.ObjectKey, field.ErrorList) {
+	var refs []client.ObjectKey
+	var errs field.ErrorList
+
+	p := field.NewPath("metadata", "annotations")
+	for k, v := range pod.Annotations {
+		if k == constants.AnnClusterEgress {
+			for _, name := range strings.Split(v, ",") {
+				if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+					errs = append(errs, field.Invalid(p.Key(k), v, "invalid ClusterEgress name: "+strings.Join(msgs, ", ")))
+					continue
+				}
+				refs = append(refs, client.ObjectKey{Name: name})
+			}
+			continue
+		}
+		if !strings.HasPrefix(k, constants.AnnEgressPrefix) {
+			continue
+		}
+
+		ns := k[len(constants.AnnEgressPrefix):]
+		if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(p.Key(k), ns, "invalid namespace: "+strings.Join(msgs, ", ")))
+			continue
+		}
+		for _, name := range strings.Split(v, ",") {
+			if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+				errs = append(errs, field.Invalid(p.Key(k), v, "invalid Egress name: "+strings.Join(msgs, ", ")))
+				continue
+			}
+			refs = append(refs, client.ObjectKey{Namespace: ns, Name: name})
+		}
+	}
+	return refs, errs
+}
+
+func podName(pod *corev1.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
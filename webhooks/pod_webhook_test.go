@@ -0,0 +1,204 @@
+package webhooks
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	egressv1 "github.com/ysksuzuki/egress-gw-cni-plugin/api/v1"
+	"github.com/ysksuzuki/egress-gw-cni-plugin/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func makePod(name string, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "c1", Image: "nginx"},
+			},
+		},
+	}
+}
+
+var _ = Describe("Pod Webhook", func() {
+	BeforeEach(func() {
+		eg := &egressv1.Egress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "egress",
+				Namespace: "default",
+			},
+			Spec: egressv1.EgressSpec{
+				Destinations: []egressv1.EgressDestination{{CIDR: "10.2.0.0/16"}},
+				Replicas:     1,
+			},
+		}
+		err := k8sClient.Create(ctx, eg)
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should allow pods without egress annotations", func() {
+		pod := makePod("no-egress", map[string]string{"foo": "bar"})
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should allow pods referencing existing Egresses", func() {
+		pod := makePod("valid", map[string]string{"egress.ysksuzuki.com/default": "egress"})
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		v := &podValidator{client: k8sClient}
+		warnings, err := v.ValidateCreate(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should label pods having egress annotations", func() {
+		pod := makePod("label-client", map[string]string{"egress.ysksuzuki.com/default": "egress"})
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Labels).To(HaveKeyWithValue(constants.LabelEgressClient, "true"))
+
+		pod = makePod("label-cluster-client", map[string]string{"egress.ysksuzuki.com": "platform"})
+		Expect((&podDefaulter{}).Default(ctx, pod)).To(Succeed())
+		Expect(pod.Labels).To(HaveKeyWithValue(constants.LabelEgressClient, "true"))
+
+		pod = makePod("label-no-egress", map[string]string{"foo": "bar"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Labels).NotTo(HaveKey(constants.LabelEgressClient))
+	})
+
+	It("should deny malformed annotations", func() {
+		pod := makePod("empty-name", map[string]string{"egress.ysksuzuki.com/default": "egress,"})
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("bad-name", map[string]string{"egress.ysksuzuki.com/default": "Egress"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("bad-namespace", map[string]string{"egress.ysksuzuki.com/a.b": "egress"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny host network pods", func() {
+		pod := makePod("host-network", map[string]string{"egress.ysksuzuki.com/default": "egress"})
+		pod.Spec.HostNetwork = true
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("host-network-no-egress", nil)
+		pod.Spec.HostNetwork = true
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should warn about missing namespaces and Egresses", func() {
+		pod := makePod("missing", map[string]string{
+			"egress.ysksuzuki.com/default": "egress,missing",
+			"egress.ysksuzuki.com/nons":    "egress",
+		})
+		err := k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		v := &podValidator{client: k8sClient}
+		warnings, err := v.ValidateCreate(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(
+			"Egress default/missing does not exist",
+			"namespace nons does not exist",
+		))
+	})
+
+	It("should deny references to Egresses not allowing the namespace", func() {
+		ns := &corev1.Namespace{}
+		ns.Name = "restricted"
+		err := k8sClient.Create(ctx, ns)
+		Expect(err).NotTo(HaveOccurred())
+
+		eg := &egressv1.Egress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "egress",
+				Namespace: "restricted",
+			},
+			Spec: egressv1.EgressSpec{
+				Destinations: []egressv1.EgressDestination{{CIDR: "10.2.0.0/16"}},
+				Replicas:     1,
+				AllowedNamespaces: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+			},
+		}
+		err = k8sClient.Create(ctx, eg)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := makePod("not-allowed", map[string]string{"egress.ysksuzuki.com/restricted": "egress"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("same-namespace", map[string]string{"egress.ysksuzuki.com/restricted": "egress"})
+		pod.Namespace = "restricted"
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		ns = &corev1.Namespace{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: "default"}, ns)
+		Expect(err).NotTo(HaveOccurred())
+		ns.Labels["team"] = "a"
+		err = k8sClient.Update(ctx, ns)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod = makePod("allowed", map[string]string{"egress.ysksuzuki.com/restricted": "egress"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate references to ClusterEgresses", func() {
+		ce := &egressv1.ClusterEgress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "platform",
+			},
+			Spec: egressv1.ClusterEgressSpec{
+				Namespace: "default",
+				EgressSpec: egressv1.EgressSpec{
+					Destinations: []egressv1.EgressDestination{{CIDR: "10.3.0.0/16"}},
+					Replicas:     1,
+					AllowedNamespaces: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tier": "platform"},
+					},
+				},
+			},
+		}
+		err := k8sClient.Create(ctx, ce)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := makePod("cluster-bad-name", map[string]string{"egress.ysksuzuki.com": "Platform"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("cluster-same-namespace", map[string]string{"egress.ysksuzuki.com": "platform"})
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod = makePod("cluster-not-allowed", map[string]string{"egress.ysksuzuki.com": "platform"})
+		pod.Namespace = "restricted"
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).To(HaveOccurred())
+
+		pod = makePod("cluster-missing", map[string]string{"egress.ysksuzuki.com": "missing"})
+		v := &podValidator{client: k8sClient}
+		warnings, err := v.ValidateCreate(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("ClusterEgress missing does not exist"))
+	})
+})